@@ -0,0 +1,386 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"programming_blog_go/config"
+	"programming_blog_go/internal/adapter/persistence/postgres"
+	"programming_blog_go/internal/adapter/service"
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+
+	"gorm.io/gorm"
+)
+
+// app holds the repositories and use cases shared by the web server and the
+// command-line tools.
+type app struct {
+	cfg *config.Config
+	db  *gorm.DB
+
+	categoryRepo domain.CategoryRepository
+	blogRepo     domain.BlogRepository
+	userRepo     domain.UserRepository
+	tagRepo      domain.TagRepository
+
+	refreshTokenRepo domain.RefreshTokenRepository
+	recoveryCodeRepo domain.RecoveryCodeRepository
+	userIdentityRepo domain.UserIdentityRepository
+	passwordHasher   domain.PasswordHasher
+	passwordPolicy   *usecase.PasswordPolicy
+	loginThrottle    *usecase.LoginThrottle
+	mailer           domain.MailerService
+	tokenDenylist    domain.TokenDenylist
+
+	// Set by initSessions, which needs the secrets
+	accessTokens      domain.AccessTokenService
+	publicKeys        domain.PublicKeyProvider
+	identityProviders []domain.IdentityProvider
+
+	getBlogPostsUC           *usecase.GetBlogPostsUseCase
+	getBlogPostsByCategoryUC *usecase.GetBlogPostsByCategoryUseCase
+	getBlogPostsByTagUC      *usecase.GetBlogPostsByTagUseCase
+	getBlogPostBySlugUC      *usecase.GetBlogPostBySlugUseCase
+	getBlogPostByIDUC        *usecase.GetBlogPostByIDUseCase
+	createBlogPostUC         *usecase.CreateBlogPostUseCase
+	updateBlogPostUC         *usecase.UpdateBlogPostUseCase
+	deleteBlogPostUC         *usecase.DeleteBlogPostUseCase
+	rerenderPostsUC          *usecase.RerenderPostsUseCase
+	registerUserUC           *usecase.RegisterUserUseCase
+	createUserUC             *usecase.CreateUserUseCase
+	authenticateUserUC       *usecase.AuthenticateUserUseCase
+	updateUserRoleUC         *usecase.UpdateUserRoleUseCase
+	setUserPasswordUC        *usecase.SetUserPasswordUseCase
+	verifyEmailUC            *usecase.VerifyEmailUseCase
+	resendVerificationUC     *usecase.ResendVerificationEmailUseCase
+	forgotPasswordUC         *usecase.ForgotPasswordUseCase
+	resetPasswordUC          *usecase.ResetPasswordUseCase
+	startSessionUC           *usecase.StartSessionUseCase
+	refreshSessionUC         *usecase.RefreshSessionUseCase
+	endSessionUC             *usecase.EndSessionUseCase
+	twoFactorChallenge       *usecase.TwoFactorChallenge
+	twoFactorStatusUC        *usecase.GetTwoFactorStatusUseCase
+	setupTwoFactorUC         *usecase.SetupTwoFactorUseCase
+	confirmTwoFactorUC       *usecase.ConfirmTwoFactorUseCase
+	disableTwoFactorUC       *usecase.DisableTwoFactorUseCase
+	regenerateRecoveryUC     *usecase.RegenerateRecoveryCodesUseCase
+	verifyTwoFactorLoginUC   *usecase.VerifyTwoFactorLoginUseCase
+	resetTwoFactorUC         *usecase.ResetTwoFactorUseCase
+	unlockLoginUC            *usecase.UnlockLoginUseCase
+	createAPITokenUC         *usecase.CreateAPITokenUseCase
+	listAPITokensUC          *usecase.ListAPITokensUseCase
+	revokeAPITokenUC         *usecase.RevokeAPITokenUseCase
+	authenticateAPITokenUC   *usecase.AuthenticateAPITokenUseCase
+	startExternalLoginUC     *usecase.StartExternalLoginUseCase
+	completeExternalLoginUC  *usecase.CompleteExternalLoginUseCase
+	sendContactMessageUC     *usecase.SendContactMessageUseCase
+	getAllCategoriesUC       *usecase.GetAllCategoriesUseCase
+	getTagCloudUC            *usecase.GetTagCloudUseCase
+	searchPostsUC            *usecase.SearchPostsUseCase
+	getSitemapUC             *usecase.GetSitemapUseCase
+	createCategoryUC         *usecase.CreateCategoryUseCase
+	updateCategoryUC         *usecase.UpdateCategoryUseCase
+	deleteCategoryUC         *usecase.DeleteCategoryUseCase
+}
+
+// newApp wires the repositories and use cases on top of the database connection.
+// Sign-in and sessions need secrets that the maintenance commands have no use
+// for; initSessions sets them up for the commands that do.
+func newApp(cfg *config.Config, db *gorm.DB) (*app, error) {
+	// Initialize repositories
+	categoryRepo := postgres.NewCategoryRepository(db)
+	blogRepo := postgres.NewBlogRepository(db)
+	userRepo := postgres.NewUserRepository(db)
+	tagRepo := postgres.NewTagRepository(db)
+	resetRepo := postgres.NewPasswordResetRepository(db)
+	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
+	tokenDenylist := postgres.NewTokenDenylist(db)
+	recoveryCodeRepo := postgres.NewRecoveryCodeRepository(db)
+	apiTokenRepo := postgres.NewAPITokenRepository(db)
+	userIdentityRepo := postgres.NewUserIdentityRepository(db)
+	loginAttemptRepo := postgres.NewLoginAttemptRepository(db)
+
+	// Initialize password hashing; hashes of the other algorithm keep working
+	bcryptHasher, err := service.NewBcryptHasher(cfg.BcryptCost)
+	if err != nil {
+		return nil, fmt.Errorf("BCRYPT_COST: %w", err)
+	}
+	argon2Hasher, err := service.NewArgon2idHasher(cfg.Argon2Memory, cfg.Argon2Iterations, cfg.Argon2Parallelism)
+	if err != nil {
+		return nil, fmt.Errorf("ARGON2_*: %w", err)
+	}
+	var passwordHasher domain.PasswordHasher
+	switch cfg.PasswordHash {
+	case "argon2id":
+		passwordHasher = service.NewPasswordHasher(argon2Hasher, bcryptHasher)
+	case "bcrypt":
+		passwordHasher = service.NewPasswordHasher(bcryptHasher, argon2Hasher)
+	default:
+		return nil, fmt.Errorf("PASSWORD_HASH: unknown algorithm %q", cfg.PasswordHash)
+	}
+	passwordBlocklist, err := service.NewPasswordBlocklist(cfg.PasswordBlocklist...)
+	if err != nil {
+		return nil, fmt.Errorf("PASSWORD_BLOCKLIST: %w", err)
+	}
+	passwordPolicy := &usecase.PasswordPolicy{
+		MinLength: cfg.PasswordMinLength,
+		MaxLength: cfg.PasswordMaxLength,
+		Blocklist: passwordBlocklist,
+	}
+
+	// Initialize login throttling
+	loginThrottle := &usecase.LoginThrottle{
+		Attempts:            loginAttemptRepo,
+		FreeAccountFailures: cfg.LoginAccountFailures,
+		FreeIPFailures:      cfg.LoginIPFailures,
+		BaseLockout:         cfg.LoginLockoutBase,
+		MaxLockout:          cfg.LoginLockoutMax,
+		ResetAfter:          cfg.LoginFailureWindow,
+	}
+
+	// Initialize mailer service
+	mailer := service.NewSMTPSender(
+		cfg.SMTPHost,
+		cfg.SMTPPort,
+		cfg.SMTPUser,
+		cfg.SMTPPass,
+		cfg.SMTPFrom,
+	)
+
+	// Initialize content renderer
+	contentRenderer := service.NewContentRenderer()
+
+	// Initialize use cases
+	return &app{
+		cfg: cfg,
+		db:  db,
+
+		categoryRepo: categoryRepo,
+		blogRepo:     blogRepo,
+		userRepo:     userRepo,
+		tagRepo:      tagRepo,
+
+		refreshTokenRepo: refreshTokenRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		userIdentityRepo: userIdentityRepo,
+		passwordHasher:   passwordHasher,
+		passwordPolicy:   passwordPolicy,
+		loginThrottle:    loginThrottle,
+		mailer:           mailer,
+		tokenDenylist:    tokenDenylist,
+
+		getBlogPostsUC:           &usecase.GetBlogPostsUseCase{BlogRepository: blogRepo},
+		getBlogPostsByCategoryUC: &usecase.GetBlogPostsByCategoryUseCase{BlogRepository: blogRepo, CategoryRepository: categoryRepo},
+		getBlogPostsByTagUC:      &usecase.GetBlogPostsByTagUseCase{BlogRepository: blogRepo, TagRepository: tagRepo},
+		getBlogPostBySlugUC:      &usecase.GetBlogPostBySlugUseCase{BlogRepository: blogRepo, ContentRenderer: contentRenderer},
+		getBlogPostByIDUC:        &usecase.GetBlogPostByIDUseCase{BlogRepository: blogRepo},
+		createBlogPostUC: &usecase.CreateBlogPostUseCase{
+			BlogRepository:     blogRepo,
+			CategoryRepository: categoryRepo,
+			TagRepository:      tagRepo,
+			ContentRenderer:    contentRenderer,
+		},
+		updateBlogPostUC: &usecase.UpdateBlogPostUseCase{
+			BlogRepository:     blogRepo,
+			CategoryRepository: categoryRepo,
+			TagRepository:      tagRepo,
+			ContentRenderer:    contentRenderer,
+		},
+		deleteBlogPostUC: &usecase.DeleteBlogPostUseCase{BlogRepository: blogRepo},
+		rerenderPostsUC:  &usecase.RerenderPostsUseCase{BlogRepository: blogRepo, ContentRenderer: contentRenderer},
+		createUserUC: &usecase.CreateUserUseCase{
+			UserRepository: userRepo,
+			PasswordHasher: passwordHasher,
+			PasswordPolicy: passwordPolicy,
+		},
+		authenticateUserUC: &usecase.AuthenticateUserUseCase{
+			UserRepository:   userRepo,
+			PasswordHasher:   passwordHasher,
+			UnverifiedPolicy: usecase.UnverifiedLoginPolicy(cfg.UnverifiedLoginPolicy),
+			Throttle:         loginThrottle,
+		},
+		updateUserRoleUC: &usecase.UpdateUserRoleUseCase{UserRepository: userRepo},
+		setUserPasswordUC: &usecase.SetUserPasswordUseCase{
+			UserRepository:     userRepo,
+			APITokenRepository: apiTokenRepo,
+			PasswordHasher:     passwordHasher,
+			PasswordPolicy:     passwordPolicy,
+		},
+		forgotPasswordUC: &usecase.ForgotPasswordUseCase{
+			UserRepository:          userRepo,
+			PasswordResetRepository: resetRepo,
+			MailerService:           mailer,
+			BaseURL:                 cfg.BaseURL,
+			TTL:                     cfg.PasswordResetTTL,
+		},
+		resetPasswordUC: &usecase.ResetPasswordUseCase{
+			UserRepository:          userRepo,
+			PasswordResetRepository: resetRepo,
+			PasswordHasher:          passwordHasher,
+			PasswordPolicy:          passwordPolicy,
+		},
+		twoFactorStatusUC: &usecase.GetTwoFactorStatusUseCase{UserRepository: userRepo},
+		resetTwoFactorUC:  &usecase.ResetTwoFactorUseCase{UserRepository: userRepo, RecoveryCodeRepository: recoveryCodeRepo},
+		unlockLoginUC:     &usecase.UnlockLoginUseCase{UserRepository: userRepo, Throttle: loginThrottle},
+		createAPITokenUC:  &usecase.CreateAPITokenUseCase{APITokenRepository: apiTokenRepo},
+		listAPITokensUC:   &usecase.ListAPITokensUseCase{APITokenRepository: apiTokenRepo},
+		revokeAPITokenUC:  &usecase.RevokeAPITokenUseCase{APITokenRepository: apiTokenRepo},
+		authenticateAPITokenUC: &usecase.AuthenticateAPITokenUseCase{
+			APITokenRepository: apiTokenRepo,
+			UserRepository:     userRepo,
+			UnverifiedPolicy:   usecase.UnverifiedLoginPolicy(cfg.UnverifiedLoginPolicy),
+		},
+		sendContactMessageUC: &usecase.SendContactMessageUseCase{MailerService: mailer},
+		getAllCategoriesUC:   &usecase.GetAllCategoriesUseCase{CategoryRepository: categoryRepo},
+		getTagCloudUC:        &usecase.GetTagCloudUseCase{TagRepository: tagRepo},
+		searchPostsUC:        &usecase.SearchPostsUseCase{BlogRepository: blogRepo},
+		getSitemapUC:         &usecase.GetSitemapUseCase{BlogRepository: blogRepo, CategoryRepository: categoryRepo},
+		createCategoryUC:     &usecase.CreateCategoryUseCase{CategoryRepository: categoryRepo},
+		updateCategoryUC:     &usecase.UpdateCategoryUseCase{CategoryRepository: categoryRepo},
+		deleteCategoryUC:     &usecase.DeleteCategoryUseCase{CategoryRepository: categoryRepo, BlogRepository: blogRepo},
+	}, nil
+}
+
+// initSessions checks the signing and encryption secrets and wires the use cases
+// that depend on them: sessions, email verification, two-factor authentication and
+// external login.
+func (a *app) initSessions() error {
+	cfg := a.cfg
+
+	// Initialize access token signing. JWT_SECRET also signs email verification
+	// links, so it has to be strong even when JWT_KEYS are set.
+	if err := service.CheckHMACSecret([]byte(cfg.JWTSecret)); err != nil {
+		return fmt.Errorf("JWT_SECRET: %w", err)
+	}
+	jwtKeys, err := service.LoadJWTKeys(cfg.JWTKeys)
+	if err != nil {
+		return err
+	}
+	if len(jwtKeys) == 0 {
+		jwtKeys = []*service.JWTKey{service.NewHMACKey("default", []byte(cfg.JWTSecret))}
+	}
+	accessTokens, err := service.NewJWTTokenService(jwtKeys, cfg.JWTIssuer, cfg.JWTAudience, cfg.AccessTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	// Initialize encryption of stored TOTP secrets. The key has its own variable so
+	// rotating JWT_SECRET never makes the stored secrets unreadable.
+	if cfg.TOTPEncryptionKey == "" {
+		return errors.New("TOTP_ENCRYPTION_KEY is required")
+	}
+	totpKey, err := base64.StdEncoding.DecodeString(cfg.TOTPEncryptionKey)
+	if err != nil {
+		return fmt.Errorf("TOTP_ENCRYPTION_KEY: %w", err)
+	}
+	secretCipher, err := service.NewAESCipher(totpKey)
+	if err != nil {
+		return fmt.Errorf("TOTP_ENCRYPTION_KEY: %w", err)
+	}
+
+	// Login challenges and OIDC state are signed with their own secret, independent
+	// of the access token keys
+	if err := service.CheckHMACSecret([]byte(cfg.LoginStateSecret)); err != nil {
+		return fmt.Errorf("LOGIN_STATE_SECRET: %w", err)
+	}
+	twoFactorChallenge := &usecase.TwoFactorChallenge{Secret: []byte(cfg.LoginStateSecret), TTL: cfg.TwoFactorChallengeTTL}
+
+	// Initialize external login providers
+	identityProviders := make([]domain.IdentityProvider, 0, len(cfg.OIDCProviders))
+	for _, p := range cfg.OIDCProviders {
+		identityProviders = append(identityProviders, service.NewOIDCProvider(service.OIDCProviderConfig{
+			ID:           p.ID,
+			Name:         p.Name,
+			IssuerURL:    p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  cfg.BaseURL + "/auth/oidc/" + p.ID + "/callback",
+			Scopes:       p.Scopes,
+		}, nil))
+	}
+	oidcLoginState := &usecase.OIDCLoginState{Secret: []byte(cfg.LoginStateSecret), TTL: cfg.OIDCLoginTTL}
+
+	// Initialize email verification links
+	emailVerification := &usecase.EmailVerification{
+		Secret:  []byte(cfg.JWTSecret),
+		TTL:     cfg.EmailVerificationTTL,
+		BaseURL: cfg.BaseURL,
+		Mailer:  a.mailer,
+	}
+
+	a.accessTokens = accessTokens
+	a.publicKeys = accessTokens
+	a.identityProviders = identityProviders
+
+	a.registerUserUC = &usecase.RegisterUserUseCase{
+		UserRepository:    a.userRepo,
+		PasswordHasher:    a.passwordHasher,
+		PasswordPolicy:    a.passwordPolicy,
+		EmailVerification: emailVerification,
+	}
+	a.verifyEmailUC = &usecase.VerifyEmailUseCase{UserRepository: a.userRepo, EmailVerification: emailVerification}
+	a.resendVerificationUC = &usecase.ResendVerificationEmailUseCase{
+		UserRepository:    a.userRepo,
+		EmailVerification: emailVerification,
+		Cooldown:          cfg.EmailVerificationCooldown,
+	}
+	a.startSessionUC = &usecase.StartSessionUseCase{
+		AccessTokens:           accessTokens,
+		RefreshTokenRepository: a.refreshTokenRepo,
+		RefreshTTL:             cfg.RefreshTokenTTL,
+	}
+	// Logging out forgets the successors kept for the reuse grace
+	refreshRotations := &usecase.RefreshRotations{}
+	a.refreshSessionUC = &usecase.RefreshSessionUseCase{
+		UserRepository:         a.userRepo,
+		AccessTokens:           accessTokens,
+		RefreshTokenRepository: a.refreshTokenRepo,
+		RefreshTTL:             cfg.RefreshTokenTTL,
+		UnverifiedPolicy:       usecase.UnverifiedLoginPolicy(cfg.UnverifiedLoginPolicy),
+		ReuseGrace:             cfg.RefreshReuseGrace,
+		Rotations:              refreshRotations,
+	}
+	a.endSessionUC = &usecase.EndSessionUseCase{
+		RefreshTokenRepository: a.refreshTokenRepo,
+		TokenDenylist:          a.tokenDenylist,
+		Rotations:              refreshRotations,
+	}
+	a.twoFactorChallenge = twoFactorChallenge
+	a.setupTwoFactorUC = &usecase.SetupTwoFactorUseCase{UserRepository: a.userRepo, SecretCipher: secretCipher, Issuer: cfg.SiteTitle}
+	a.confirmTwoFactorUC = &usecase.ConfirmTwoFactorUseCase{
+		UserRepository:         a.userRepo,
+		RecoveryCodeRepository: a.recoveryCodeRepo,
+		SecretCipher:           secretCipher,
+	}
+	a.disableTwoFactorUC = &usecase.DisableTwoFactorUseCase{
+		UserRepository:         a.userRepo,
+		RecoveryCodeRepository: a.recoveryCodeRepo,
+		SecretCipher:           secretCipher,
+		Throttle:               a.loginThrottle,
+	}
+	a.regenerateRecoveryUC = &usecase.RegenerateRecoveryCodesUseCase{
+		UserRepository:         a.userRepo,
+		RecoveryCodeRepository: a.recoveryCodeRepo,
+		SecretCipher:           secretCipher,
+		Throttle:               a.loginThrottle,
+	}
+	a.verifyTwoFactorLoginUC = &usecase.VerifyTwoFactorLoginUseCase{
+		UserRepository:         a.userRepo,
+		RecoveryCodeRepository: a.recoveryCodeRepo,
+		SecretCipher:           secretCipher,
+		Challenges:             twoFactorChallenge,
+		UnverifiedPolicy:       usecase.UnverifiedLoginPolicy(cfg.UnverifiedLoginPolicy),
+		Throttle:               a.loginThrottle,
+	}
+	a.startExternalLoginUC = &usecase.StartExternalLoginUseCase{Providers: identityProviders, States: oidcLoginState}
+	a.completeExternalLoginUC = &usecase.CompleteExternalLoginUseCase{
+		Providers:              identityProviders,
+		States:                 oidcLoginState,
+		UserRepository:         a.userRepo,
+		UserIdentityRepository: a.userIdentityRepo,
+		AllowSignup:            cfg.OIDCAllowSignup,
+		UnverifiedPolicy:       usecase.UnverifiedLoginPolicy(cfg.UnverifiedLoginPolicy),
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"programming_blog_go/internal/usecase"
+)
+
+const categoryUsage = `usage: category add --name NAME [--slug SLUG]`
+
+// runCategory implements the category subcommands.
+func runCategory(a *app, args []string) error {
+	if len(args) == 0 || args[0] != "add" {
+		return errors.New(categoryUsage)
+	}
+
+	fs := flag.NewFlagSet("category add", flag.ExitOnError)
+	name := fs.String("name", "", "display name")
+	slug := fs.String("slug", "", "URL slug (derived from the name when omitted)")
+	fs.Parse(args[1:])
+
+	if *name == "" {
+		return errors.New(categoryUsage)
+	}
+	category, err := a.createCategoryUC.Execute(usecase.CreateCategoryRequest{Name: *name, Slug: *slug}, operator)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created category %s (id %d, /category/%s)\n", category.Name, category.ID, category.Slug)
+	return nil
+}
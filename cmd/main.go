@@ -2,24 +2,60 @@ package main
 
 import (
 	"fmt"
-	"html/template"
 	"log"
-
-	// "os" // Removed as it's no longer directly used
+	"os"
 
 	"programming_blog_go/config"
-	"programming_blog_go/internal/adapter/handler"
-	"programming_blog_go/internal/adapter/persistence/postgres"
-	"programming_blog_go/internal/adapter/service"
-	"programming_blog_go/internal/middleware"
-	"programming_blog_go/internal/usecase"
 
-	"github.com/gin-gonic/gin"
 	pgdriver "gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+const usage = `usage: blog <command> [arguments]
+
+Commands:
+  serve [--port PORT]                     start the web server (default)
+  migrate up | down [N] | status | baseline VERSION
+                                          apply or revert database migrations
+  user create --username NAME --email EMAIL [--role ROLE] [--password PASSWORD]
+                                          create a user, e.g. the first admin
+  user reset-password --username NAME [--password PASSWORD]
+                                          set a new password for a user
+  user reset-2fa --username NAME          remove a user's two-factor authentication
+  user unlock (--username NAME | --ip ADDRESS)
+                                          lift a lockout after failed logins
+  category add --name NAME [--slug SLUG]  create a category
+  post publish SLUG                       publish a draft post
+  post rerender                           render stale cached post HTML again
+  seed [--author NAME]                    add sample categories and posts
+
+Passwords that are not given as flags are read from the terminal or standard input.`
+
+// commands maps subcommand names to their implementations.
+var commands = map[string]func(a *app, args []string) error{
+	"serve":    runServe,
+	"migrate":  runMigrate,
+	"user":     runUser,
+	"category": runCategory,
+	"post":     runPost,
+	"seed":     runSeed,
+}
+
 func main() {
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	if command == "help" || command == "-h" || command == "--help" {
+		fmt.Println(usage)
+		return
+	}
+	run, ok := commands[command]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", command, usage)
+		os.Exit(2)
+	}
+
 	// Load configuration
 	cfg := config.LoadConfig()
 
@@ -36,89 +72,11 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Auto-migrate the database schema
-	// NOTE: In a production environment, you would use a dedicated migration tool
-	// like `golang-migrate/migrate` or `gorm.io/gorm/migrator` with versioned migrations.
-	// For simplicity in this project, we are using GORM's AutoMigrate.
-	// err = db.AutoMigrate(&domain.Blog{}, &domain.Category{}, &domain.User{})
-	// if err != nil {
-	// 	log.Fatalf("Failed to auto migrate database: %v", err)
-	// }
-
-	// Initialize repositories
-	categoryRepo := postgres.NewCategoryRepository(db)
-	blogRepo := postgres.NewBlogRepository(db)
-	userRepo := postgres.NewUserRepository(db)
-
-	// Initialize mailer service
-	mailer := service.NewSMTPSender(
-		cfg.SMTPHost,
-		cfg.SMTPPort,
-		cfg.SMTPUser,
-		cfg.SMTPPass,
-		cfg.SMTPFrom,
-	)
-
-	// Initialize use cases
-	getBlogPostsUC := &usecase.GetBlogPostsUseCase{BlogRepository: blogRepo}
-	getBlogPostsByCategoryUC := &usecase.GetBlogPostsByCategoryUseCase{BlogRepository: blogRepo, CategoryRepository: categoryRepo}
-	getBlogPostBySlugUC := &usecase.GetBlogPostBySlugUseCase{BlogRepository: blogRepo}
-	createBlogPostUC := &usecase.CreateBlogPostUseCase{BlogRepository: blogRepo, CategoryRepository: categoryRepo}
-	registerUserUC := &usecase.RegisterUserUseCase{UserRepository: userRepo}
-	authenticateUserUC := &usecase.AuthenticateUserUseCase{UserRepository: userRepo}
-	sendContactMessageUC := &usecase.SendContactMessageUseCase{MailerService: mailer}
-	getAllCategoriesUC := &usecase.GetAllCategoriesUseCase{CategoryRepository: categoryRepo}
-
-	// Initialize handlers
-	blogHandler := handler.NewBlogHandler(getBlogPostsUC, getBlogPostsByCategoryUC, getBlogPostBySlugUC, createBlogPostUC)
-	userHandler := handler.NewUserHandler(registerUserUC, authenticateUserUC)
-	contactHandler := handler.NewContactHandler(sendContactMessageUC)
-
-	// Set up Gin router
-	r := gin.Default()
-
-	// Load templates
-	r.SetHTMLTemplate(template.Must(template.ParseGlob("web/templates/*.html")))
-
-	// Serve static files
-	r.Static("/static", "./web/static") // Assuming static files are in web/static
-
-	// Apply CategoryContextMiddleware to all routes that render HTML
-	htmlRoutes := r.Group("/")
-	htmlRoutes.Use(middleware.CategoryContextMiddleware(getAllCategoriesUC))
-	{
-		htmlRoutes.GET("/", blogHandler.GetBlogPosts)
-		htmlRoutes.GET("/post/:post_slug", blogHandler.GetBlogPost)
-		htmlRoutes.GET("/category/:cat_slug", blogHandler.GetBlogPostsByCategory)
-
-		// Pages that serve HTML forms (for now, these are simple renders)
-		htmlRoutes.GET("/addpage", blogHandler.AddPostPage)
-		htmlRoutes.GET("/register", userHandler.ShowRegisterPage)
-		htmlRoutes.GET("/login", userHandler.ShowLoginPage)
-		htmlRoutes.GET("/contact", contactHandler.ShowContactPage)
-	}
-
-	// API endpoints
-	api := r.Group("/api")
-	{
-		api.POST("/register", userHandler.RegisterUser)
-		api.POST("/login", userHandler.LoginUser)
-		api.POST("/contact", contactHandler.SendContactMessage)
-
-		// Protected routes
-		protected := api.Group("/")
-		protected.Use(middleware.JWTAuthMiddleware([]byte(cfg.JWTSecret)))
-		{
-			protected.POST("/posts", blogHandler.CreateBlogPost)
-			// TODO: Add other protected routes here (e.g., update/delete posts)
-		}
+	a, err := newApp(cfg, db)
+	if err != nil {
+		log.Fatalf("Failed to initialize: %v", err)
 	}
-
-	// Start server
-	port := cfg.AppPort
-	if port == "" {
-		port = "8080" // Default port
+	if err := run(a, args); err != nil {
+		log.Fatalf("%s: %v", command, err)
 	}
-	log.Printf("Server listening on :%s", port)
-	log.Fatal(r.Run(":" + port))
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"programming_blog_go/internal/adapter/persistence/postgres"
+)
+
+const migrateUsage = `usage: migrate up | down [N] | status | baseline VERSION
+
+  up                apply all pending migrations
+  down [N]          revert the last N applied migrations (default 1)
+  status            list migrations and when they were applied
+  baseline VERSION  mark migrations up to VERSION as applied without running them,
+                    for databases created by hand before the migration runner`
+
+// runMigrate implements the migrate subcommand.
+func runMigrate(a *app, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	migrator, err := postgres.NewMigrator(a.db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	case "baseline":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		recorded, err := migrator.Baseline(ctx, version)
+		for _, m := range recorded {
+			fmt.Printf("marked %04d_%s as applied\n", m.Version, m.Name)
+		}
+		return err
+	default:
+		return errors.New(migrateUsage)
+	}
+}
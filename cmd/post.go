@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"programming_blog_go/internal/usecase"
+)
+
+const postUsage = `usage: post publish SLUG
+       post rerender`
+
+// runPost implements the post subcommands.
+func runPost(a *app, args []string) error {
+	if len(args) == 1 && args[0] == "rerender" {
+		return runPostRerender(a)
+	}
+	if len(args) != 2 || args[0] != "publish" {
+		return errors.New(postUsage)
+	}
+
+	post, err := a.getBlogPostBySlugUC.Execute(args[1])
+	if err != nil {
+		return err
+	}
+	if post.IsPublished {
+		fmt.Printf("post %s is already published\n", post.Slug)
+		return nil
+	}
+
+	published := true
+	if _, err := a.updateBlogPostUC.Execute(post.ID, usecase.UpdateBlogPostRequest{IsPublished: &published}, operator); err != nil {
+		return err
+	}
+	fmt.Printf("published post %s\n", post.Slug)
+	return nil
+}
+
+// runPostRerender renders the cached HTML of posts again after the renderer changed.
+func runPostRerender(a *app) error {
+	rendered, err := a.rerenderPostsUC.Execute(operator)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("rendered %d posts\n", rendered)
+	return nil
+}
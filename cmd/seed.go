@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+)
+
+// Sample data for a fresh installation. Seeding is idempotent: anything whose slug
+// already exists is left alone.
+var (
+	seedCategories = []usecase.CreateCategoryRequest{
+		{Name: "Go", Slug: "go"},
+		{Name: "Databases", Slug: "databases"},
+	}
+	seedPosts = []struct {
+		usecase.CreateBlogPostRequest
+		CategorySlug string
+	}{
+		{
+			CreateBlogPostRequest: usecase.CreateBlogPostRequest{
+				Title: "Hello, world",
+				Slug:  "hello-world",
+				Content: "Welcome to the blog! Posts are written in **Markdown**.\n\n" +
+					"```go\npackage main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"Hello, world\")\n}\n```\n",
+				Tags: []string{"go", "meta"},
+			},
+			CategorySlug: "go",
+		},
+		{
+			CreateBlogPostRequest: usecase.CreateBlogPostRequest{
+				Title: "Keyset pagination in PostgreSQL",
+				Slug:  "keyset-pagination-in-postgresql",
+				Content: "Instead of `OFFSET`, remember the last row you showed:\n\n" +
+					"```sql\nSELECT * FROM blogs\nWHERE (time_created, id) < ($1, $2)\nORDER BY time_created DESC, id DESC\nLIMIT 10;\n```\n",
+				Tags: []string{"postgresql", "performance"},
+			},
+			CategorySlug: "databases",
+		},
+	}
+)
+
+// runSeed implements the seed subcommand.
+func runSeed(a *app, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	authorName := fs.String("author", "admin", "existing user who becomes the author of the sample posts")
+	fs.Parse(args)
+
+	author, err := a.userRepo.FindByUsername(*authorName)
+	if err != nil {
+		return err
+	}
+	if author == nil {
+		return fmt.Errorf("user %q not found, create it first with: user create --username %s --email ... --role admin", *authorName, *authorName)
+	}
+	actor := usecase.Actor{UserID: author.ID, Role: author.Role}
+
+	categoryIDs := make(map[string]uint)
+	for _, req := range seedCategories {
+		category, err := a.categoryRepo.FindBySlug(req.Slug)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			if category, err = a.createCategoryUC.Execute(req, operator); err != nil {
+				return fmt.Errorf("category %s: %w", req.Slug, err)
+			}
+			fmt.Printf("created category %s\n", category.Slug)
+		}
+		categoryIDs[category.Slug] = category.ID
+	}
+
+	for _, seed := range seedPosts {
+		existing, err := a.blogRepo.FindBySlug(seed.Slug)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		req := seed.CreateBlogPostRequest
+		req.CategoryID = categoryIDs[seed.CategorySlug]
+		req.ContentFormat = domain.ContentFormatMarkdown
+		// Authors without the publish permission get drafts for an editor to review
+		req.IsPublished = actor.Can(domain.PermissionPublishPosts)
+		post, err := a.createBlogPostUC.Execute(req, actor)
+		if err != nil {
+			return fmt.Errorf("post %s: %w", req.Slug, err)
+		}
+		fmt.Printf("created post %s\n", post.Slug)
+	}
+	return nil
+}
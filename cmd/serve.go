@@ -0,0 +1,229 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"programming_blog_go/internal/adapter/handler"
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// runServe implements the serve subcommand: it starts the web server.
+func runServe(a *app, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	portFlag := fs.String("port", a.cfg.AppPort, "port to listen on")
+	fs.Parse(args)
+
+	if err := a.initSessions(); err != nil {
+		return err
+	}
+
+	// Initialize handlers
+	blogHandler := handler.NewBlogHandler(
+		a.getBlogPostsUC,
+		a.getBlogPostsByCategoryUC,
+		a.getBlogPostsByTagUC,
+		a.getBlogPostBySlugUC,
+		a.getBlogPostByIDUC,
+		a.createBlogPostUC,
+		a.updateBlogPostUC,
+		a.deleteBlogPostUC,
+	)
+	userHandler := handler.NewUserHandler(
+		a.registerUserUC,
+		a.authenticateUserUC,
+		a.updateUserRoleUC,
+		a.unlockLoginUC,
+		a.verifyEmailUC,
+		a.resendVerificationUC,
+		a.startSessionUC,
+		a.refreshSessionUC,
+		a.endSessionUC,
+		a.twoFactorChallenge,
+		a.cfg.SecureCookies,
+	)
+	twoFactorHandler := handler.NewTwoFactorHandler(
+		a.twoFactorStatusUC,
+		a.setupTwoFactorUC,
+		a.confirmTwoFactorUC,
+		a.disableTwoFactorUC,
+		a.regenerateRecoveryUC,
+		a.verifyTwoFactorLoginUC,
+		a.startSessionUC,
+		a.cfg.SecureCookies,
+	)
+	passwordResetHandler := handler.NewPasswordResetHandler(a.forgotPasswordUC, a.resetPasswordUC)
+	contactHandler := handler.NewContactHandler(a.sendContactMessageUC)
+	searchHandler := handler.NewSearchHandler(a.searchPostsUC)
+	categoryHandler := handler.NewCategoryHandler(a.getAllCategoriesUC, a.createCategoryUC, a.updateCategoryUC, a.deleteCategoryUC)
+	feedHandler := handler.NewFeedHandler(a.getBlogPostsUC, a.getBlogPostsByCategoryUC, a.cfg.BaseURL, a.cfg.SiteTitle)
+	sitemapHandler := handler.NewSitemapHandler(a.getSitemapUC, a.cfg.BaseURL, a.cfg.RobotsDisallow)
+	jwksHandler := handler.NewJWKSHandler(a.publicKeys)
+	apiTokenHandler := handler.NewAPITokenHandler(a.createAPITokenUC, a.listAPITokensUC, a.revokeAPITokenUC)
+	oidcHandler := handler.NewOIDCHandler(
+		a.startExternalLoginUC,
+		a.completeExternalLoginUC,
+		a.twoFactorChallenge,
+		a.startSessionUC,
+		a.cfg.SecureCookies,
+	)
+
+	// Requests are authenticated by a Bearer token (a JWT or a personal API token)
+	// or by the browser session cookies
+	auth := &middleware.Authenticator{
+		Tokens:        a.accessTokens,
+		Users:         a.userRepo,
+		Denylist:      a.tokenDenylist,
+		APITokens:     a.authenticateAPITokenUC,
+		Refresh:       a.refreshSessionUC,
+		SecureCookies: a.cfg.SecureCookies,
+	}
+
+	// Set up Gin router
+	r := gin.Default()
+	// The client address throttles logins, so only proxies we run may set it
+	if err := r.SetTrustedProxies(a.cfg.TrustedProxies); err != nil {
+		return fmt.Errorf("trusted proxies: %w", err)
+	}
+
+	// Load templates
+	htmlTemplates, err := handler.LoadHTMLTemplates("web/templates")
+	if err != nil {
+		return fmt.Errorf("loading templates: %w", err)
+	}
+	r.HTMLRender = htmlTemplates
+
+	// Serve static files
+	r.Static("/static", "./web/static") // Assuming static files are in web/static
+
+	// Feeds, sitemap, robots.txt and the token signing keys (no layout context needed)
+	r.GET("/feed.xml", feedHandler.RSS)
+	r.GET("/atom.xml", feedHandler.Atom)
+	r.GET("/category/:cat_slug/feed.xml", feedHandler.CategoryRSS)
+	r.GET("/category/:cat_slug/atom.xml", feedHandler.CategoryAtom)
+	r.GET("/sitemap.xml", sitemapHandler.Sitemap)
+	r.GET("/sitemap-:part", sitemapHandler.SitemapPart)
+	r.GET("/robots.txt", sitemapHandler.Robots)
+	r.GET("/.well-known/jwks.json", jwksHandler.JWKS)
+
+	// Apply CategoryContextMiddleware and TagContextMiddleware to all routes that render HTML;
+	// CSRFMiddleware gives the forms their tokens, OptionalAuthMiddleware lets the
+	// navigation show who is logged in and IdentityProviderContextMiddleware lists
+	// the external logins for the login page
+	htmlRoutes := r.Group("/")
+	htmlRoutes.Use(
+		middleware.CategoryContextMiddleware(a.getAllCategoriesUC),
+		middleware.TagContextMiddleware(a.getTagCloudUC),
+		middleware.CSRFMiddleware(a.cfg.SecureCookies),
+		middleware.OptionalAuthMiddleware(auth),
+		middleware.IdentityProviderContextMiddleware(a.identityProviders),
+	)
+	{
+		htmlRoutes.GET("/", blogHandler.GetBlogPosts)
+		htmlRoutes.GET("/post/:post_slug", blogHandler.GetBlogPost)
+		htmlRoutes.GET("/category/:cat_slug", blogHandler.GetBlogPostsByCategory)
+		htmlRoutes.GET("/tag/:tag_slug", blogHandler.GetBlogPostsByTag)
+		htmlRoutes.GET("/search", searchHandler.SearchPage)
+
+		// Pages that serve HTML forms (for now, these are simple renders)
+		htmlRoutes.GET("/addpage", middleware.PageAuthMiddleware(auth), blogHandler.AddPostPage)
+		htmlRoutes.GET("/register", userHandler.ShowRegisterPage)
+		htmlRoutes.GET("/login", userHandler.ShowLoginPage)
+		htmlRoutes.POST("/login", userHandler.LoginForm)
+		htmlRoutes.POST("/login/2fa", twoFactorHandler.VerifyLoginForm)
+		htmlRoutes.GET("/auth/oidc/:provider", oidcHandler.StartLogin)
+		htmlRoutes.GET("/auth/oidc/:provider/callback", oidcHandler.Callback)
+		htmlRoutes.GET("/logout", userHandler.ShowLogoutPage)
+		htmlRoutes.POST("/logout", userHandler.LogoutForm)
+		htmlRoutes.GET("/verify-email", userHandler.VerifyEmail)
+		htmlRoutes.GET("/forgot-password", passwordResetHandler.ShowForgotPasswordPage)
+		htmlRoutes.GET("/reset-password", passwordResetHandler.ShowResetPasswordPage)
+		htmlRoutes.GET("/contact", contactHandler.ShowContactPage)
+	}
+
+	// API endpoints
+	api := r.Group("/api")
+	api.Use(middleware.CSRFMiddleware(a.cfg.SecureCookies))
+	{
+		api.POST("/register", userHandler.RegisterUser)
+		api.POST("/login", userHandler.LoginUser)
+		api.POST("/login/2fa", twoFactorHandler.VerifyLogin)
+		api.POST("/token/refresh", userHandler.RefreshToken)
+		api.POST("/verify-email/resend", userHandler.ResendVerification)
+		api.POST("/password/forgot", passwordResetHandler.ForgotPassword)
+		api.POST("/password/reset", passwordResetHandler.ResetPassword)
+		api.POST("/contact", contactHandler.SendContactMessage)
+		api.GET("/posts", blogHandler.ListBlogPosts)
+		api.GET("/search", searchHandler.Search)
+		api.GET("/categories", categoryHandler.GetCategories)
+
+		// Protected routes
+		protected := api.Group("/")
+		protected.Use(middleware.JWTAuthMiddleware(auth))
+		{
+			// Managing the account needs a login session, not an API token
+			account := protected.Group("/")
+			account.Use(middleware.RejectAPITokenMiddleware())
+			{
+				account.POST("/logout", userHandler.Logout)
+
+				// TOTP enrollment for the logged-in user; the use cases limit it to authors and above
+				twoFactor := account.Group("/account/2fa")
+				{
+					twoFactor.GET("", twoFactorHandler.Status)
+					twoFactor.POST("/setup", twoFactorHandler.Setup)
+					twoFactor.POST("/confirm", twoFactorHandler.Confirm)
+					twoFactor.POST("/disable", twoFactorHandler.Disable)
+					twoFactor.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+				}
+
+				// Personal API tokens for scripts and CI, scoped to permissions like posts:write
+				tokens := account.Group("/account/tokens")
+				{
+					tokens.GET("", apiTokenHandler.ListAPITokens)
+					tokens.POST("", apiTokenHandler.CreateAPIToken)
+					tokens.DELETE("/:id", apiTokenHandler.RevokeAPIToken)
+				}
+			}
+
+			// Authors and above; ownership and publishing rules are enforced by the use cases
+			posts := protected.Group("/posts")
+			posts.Use(middleware.RequirePermission(domain.PermissionWritePosts))
+			{
+				posts.POST("", blogHandler.CreateBlogPost)
+				posts.GET("/:id", blogHandler.GetBlogPostByID)
+				posts.PUT("/:id", blogHandler.UpdateBlogPost)
+				posts.PATCH("/:id", blogHandler.UpdateBlogPost)
+				posts.DELETE("/:id", blogHandler.DeleteBlogPost)
+			}
+
+			categories := protected.Group("/categories")
+			categories.Use(middleware.RequirePermission(domain.PermissionManageCategories))
+			{
+				categories.POST("", categoryHandler.CreateCategory)
+				categories.PUT("/:id", categoryHandler.UpdateCategory)
+				categories.PATCH("/:id", categoryHandler.UpdateCategory)
+				categories.DELETE("/:id", categoryHandler.DeleteCategory)
+			}
+
+			users := protected.Group("/users")
+			users.Use(middleware.RequireRole(domain.RoleAdmin))
+			{
+				users.PUT("/:id/role", userHandler.UpdateUserRole)
+				users.POST("/:id/unlock", userHandler.UnlockUser)
+			}
+		}
+	}
+
+	// Start server
+	port := *portFlag
+	if port == "" {
+		port = "8080" // Default port
+	}
+	log.Printf("Server listening on :%s", port)
+	return r.Run(":" + port)
+}
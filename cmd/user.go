@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+
+	"github.com/gin-gonic/gin/binding"
+	"golang.org/x/term"
+)
+
+// operator is the actor for commands run from the console. Anyone who can run them
+// already has the database credentials, so they act as an administrator.
+var operator = usecase.Actor{Role: domain.RoleAdmin}
+
+const userUsage = `usage: user create --username NAME --email EMAIL [--role ROLE] [--password PASSWORD]
+       user reset-password --username NAME [--password PASSWORD]
+       user reset-2fa --username NAME
+       user unlock (--username NAME | --ip ADDRESS)`
+
+// runUser implements the user subcommands.
+func runUser(a *app, args []string) error {
+	if len(args) == 0 {
+		return errors.New(userUsage)
+	}
+	switch args[0] {
+	case "create":
+		return runUserCreate(a, args[1:])
+	case "reset-password":
+		return runUserResetPassword(a, args[1:])
+	case "reset-2fa":
+		return runUserResetTwoFactor(a, args[1:])
+	case "unlock":
+		return runUserUnlock(a, args[1:])
+	default:
+		return errors.New(userUsage)
+	}
+}
+
+func runUserCreate(a *app, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ExitOnError)
+	username := fs.String("username", "", "login name")
+	email := fs.String("email", "", "email address")
+	role := fs.String("role", string(domain.RoleReader), "reader, author, editor or admin")
+	password := fs.String("password", "", "password (prompted for when omitted)")
+	fs.Parse(args)
+
+	if *username == "" || *email == "" {
+		return errors.New(userUsage)
+	}
+	if !domain.Role(*role).IsValid() {
+		return fmt.Errorf("unknown role %q", *role)
+	}
+	if *password == "" {
+		var err error
+		if *password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+
+	req := usecase.CreateUserRequest{
+		RegisterUserRequest: usecase.RegisterUserRequest{Username: *username, Email: *email, Password: *password},
+		Role:                domain.Role(*role),
+	}
+	// Apply the same rules as the registration form
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return err
+	}
+	user, err := a.createUserUC.Execute(req, operator)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created user %s (id %d, role %s)\n", user.Username, user.ID, user.Role)
+	return nil
+}
+
+func runUserResetPassword(a *app, args []string) error {
+	fs := flag.NewFlagSet("user reset-password", flag.ExitOnError)
+	username := fs.String("username", "", "login name")
+	password := fs.String("password", "", "new password (prompted for when omitted)")
+	fs.Parse(args)
+
+	if *username == "" {
+		return errors.New(userUsage)
+	}
+	user, err := a.userRepo.FindByUsername(*username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user %q not found", *username)
+	}
+	if *password == "" {
+		if *password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+
+	if err := a.setUserPasswordUC.Execute(user.ID, usecase.SetUserPasswordRequest{Password: *password}, operator); err != nil {
+		return err
+	}
+	fmt.Printf("password of %s updated\n", user.Username)
+	return nil
+}
+
+// runUserResetTwoFactor removes a user's second factor, for when they lost both
+// their authenticator and their recovery codes.
+func runUserResetTwoFactor(a *app, args []string) error {
+	fs := flag.NewFlagSet("user reset-2fa", flag.ExitOnError)
+	username := fs.String("username", "", "login name")
+	fs.Parse(args)
+
+	if *username == "" {
+		return errors.New(userUsage)
+	}
+	user, err := a.userRepo.FindByUsername(*username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user %q not found", *username)
+	}
+	if err := a.resetTwoFactorUC.Execute(user.ID, operator); err != nil {
+		return err
+	}
+	fmt.Printf("two-factor authentication of %s removed\n", user.Username)
+	return nil
+}
+
+// runUserUnlock lifts the login lockout of an account or a client address.
+func runUserUnlock(a *app, args []string) error {
+	fs := flag.NewFlagSet("user unlock", flag.ExitOnError)
+	username := fs.String("username", "", "login name")
+	ip := fs.String("ip", "", "client address")
+	fs.Parse(args)
+
+	if *ip != "" && *username == "" {
+		if err := a.unlockLoginUC.Execute(usecase.UnlockLoginRequest{IP: *ip}, operator); err != nil {
+			return err
+		}
+		fmt.Printf("logins from %s unlocked\n", *ip)
+		return nil
+	}
+	if *username == "" || *ip != "" {
+		return errors.New(userUsage)
+	}
+	user, err := a.userRepo.FindByUsername(*username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user %q not found", *username)
+	}
+	if err := a.unlockLoginUC.Execute(usecase.UnlockLoginRequest{UserID: user.ID}, operator); err != nil {
+		return err
+	}
+	fmt.Printf("logins of %s unlocked\n", user.Username)
+	return nil
+}
+
+// readPassword asks for a password twice on a terminal, or reads one line when the
+// input is piped in.
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "Repeat password: ")
+	repeated, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(password) != string(repeated) {
+		return "", errors.New("passwords do not match")
+	}
+	return string(password), nil
+}
@@ -3,6 +3,10 @@ package config
 import (
 	"log"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -21,6 +25,83 @@ type Config struct {
 	SMTPPass   string
 	SMTPFrom   string
 	AppPort    string
+	BaseURL    string // Public root URL used for absolute links in feeds, e.g. https://blog.example.com
+	SiteTitle  string
+	// Path prefixes listed as Disallow in robots.txt
+	RobotsDisallow []string
+	// How long email verification links stay valid
+	EmailVerificationTTL time.Duration
+	// Minimum time between two verification emails resent to the same account
+	EmailVerificationCooldown time.Duration
+	// What unverified accounts may do after logging in: off, limit or reject
+	UnverifiedLoginPolicy string
+	// How long password reset links stay valid
+	PasswordResetTTL time.Duration
+	// Lifetimes of access tokens and of the refresh tokens that renew them
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// How long after a refresh token is rotated its reuse counts as a concurrent
+	// request rather than theft
+	RefreshReuseGrace time.Duration
+	// Access token signing keys as "kid:path" entries; the first one signs and the
+	// rest only verify. Without any, JWTSecret signs with HS256.
+	JWTKeys []string
+	// Expected "iss" and "aud" of access tokens; both default to BaseURL
+	JWTIssuer   string
+	JWTAudience string
+	// Base64 key (32 bytes) that encrypts stored TOTP secrets. Required, and must
+	// never change: secrets encrypted with another key cannot be read back.
+	TOTPEncryptionKey string
+	// Signs the short-lived state between login steps: 2FA challenges and OIDC
+	// logins. Changing it only interrupts logins in progress.
+	LoginStateSecret string
+	// How long the second login step may take after the password was accepted
+	TwoFactorChallengeTTL time.Duration
+	// Whether session cookies are only sent over HTTPS; on when BASE_URL is https
+	SecureCookies bool
+	// OpenID Connect providers offered on the login page, in the order of OIDC_PROVIDERS
+	OIDCProviders []OIDCProvider
+	// Whether the first login with a provider may create an account when no user
+	// has the email address
+	OIDCAllowSignup bool
+	// How long the round trip to the provider may take
+	OIDCLoginTTL time.Duration
+	// Failed logins allowed per account and per client address before lockouts start
+	LoginAccountFailures int
+	LoginIPFailures      int
+	// The first lockout, doubled with each further failure up to the maximum
+	LoginLockoutBase time.Duration
+	LoginLockoutMax  time.Duration
+	// How long failed logins are remembered after the last one
+	LoginFailureWindow time.Duration
+	// Algorithm for new password hashes, "argon2id" or "bcrypt"; stored hashes of the
+	// other one still work and are upgraded at the next login
+	PasswordHash string
+	BcryptCost   int
+	// Argon2id cost; the defaults are OWASP's minimum, since unknown usernames are
+	// hashed too and every login attempt costs this much memory
+	Argon2Memory      int // KiB
+	Argon2Iterations  int
+	Argon2Parallelism int
+	// Length limits for new passwords; the maximum is in bytes
+	PasswordMinLength int
+	PasswordMaxLength int
+	// Files of further passwords to refuse, one per line, e.g. from breach corpora
+	PasswordBlocklist []string
+	// Addresses of reverse proxies whose X-Forwarded-For is trusted for the client address
+	TrustedProxies []string
+}
+
+// OIDCProvider is an OpenID Connect provider, configured by OIDC_<ID>_* variables.
+// Its endpoints and keys come from the discovery document at
+// Issuer + "/.well-known/openid-configuration".
+type OIDCProvider struct {
+	ID           string // Lowercase letters, digits and dashes; used in URLs
+	Name         string // On the login button
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
 }
 
 // LoadConfig loads configuration from .env file or environment variables.
@@ -30,20 +111,86 @@ func LoadConfig() *Config {
 		log.Printf("Warning: Error loading .env file, assuming environment variables are set: %v", err)
 	}
 
+	baseURL := strings.TrimRight(getEnv("BASE_URL", "http://localhost:8080"), "/")
+
 	return &Config{
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBUser:     getEnv("DB_USER", "user"),
-		DBPassword: getEnv("DB_PASSWORD", "password"),
-		DBName:     getEnv("DB_NAME", "blogdb"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		JWTSecret:  getEnv("JWT_SECRET", "supersecretjwtkey"), // Default for development
-		SMTPHost:   getEnv("SMTP_HOST", "localhost"),
-		SMTPPort:   getEnv("SMTP_PORT", "1025"), // Default Mailhog/Mailtrap local port
-		SMTPUser:   getEnv("SMTP_USERNAME", ""),
-		SMTPPass:   getEnv("SMTP_PASSWORD", ""),
-		SMTPFrom:   getEnv("SMTP_FROM", "noreply@example.com"),
-		AppPort:    getEnv("PORT", "8080"),
+		DBHost:                    getEnv("DB_HOST", "localhost"),
+		DBUser:                    getEnv("DB_USER", "user"),
+		DBPassword:                getEnv("DB_PASSWORD", "password"),
+		DBName:                    getEnv("DB_NAME", "blogdb"),
+		DBPort:                    getEnv("DB_PORT", "5432"),
+		JWTSecret:                 getEnv("JWT_SECRET", ""), // Required, at least 32 bytes
+		SMTPHost:                  getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:                  getEnv("SMTP_PORT", "1025"), // Default Mailhog/Mailtrap local port
+		SMTPUser:                  getEnv("SMTP_USERNAME", ""),
+		SMTPPass:                  getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:                  getEnv("SMTP_FROM", "noreply@example.com"),
+		AppPort:                   getEnv("PORT", "8080"),
+		BaseURL:                   baseURL,
+		SiteTitle:                 getEnv("SITE_TITLE", "My Awesome Blog"),
+		RobotsDisallow:            getEnvList("ROBOTS_DISALLOW", "/api/,/addpage,/login,/register,/search,/auth/"),
+		EmailVerificationTTL:      getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		EmailVerificationCooldown: getEnvDuration("EMAIL_VERIFICATION_COOLDOWN", 5*time.Minute),
+		UnverifiedLoginPolicy:     getEnv("UNVERIFIED_LOGIN_POLICY", "limit"),
+		PasswordResetTTL:          getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		AccessTokenTTL:            getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:           getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		RefreshReuseGrace:         getEnvDuration("REFRESH_REUSE_GRACE", 10*time.Second),
+		SecureCookies:             strings.HasPrefix(baseURL, "https://"),
+		JWTKeys:                   getEnvList("JWT_KEYS", ""),
+		TOTPEncryptionKey:         getEnv("TOTP_ENCRYPTION_KEY", ""),
+		LoginStateSecret:          getEnv("LOGIN_STATE_SECRET", ""),
+		TwoFactorChallengeTTL:     getEnvDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+		JWTIssuer:                 getEnv("JWT_ISSUER", baseURL),
+		JWTAudience:               getEnv("JWT_AUDIENCE", baseURL),
+		OIDCProviders:             loadOIDCProviders(),
+		OIDCAllowSignup:           getEnvBool("OIDC_ALLOW_SIGNUP", true),
+		OIDCLoginTTL:              getEnvDuration("OIDC_LOGIN_TTL", 10*time.Minute),
+		LoginAccountFailures:      getEnvInt("LOGIN_ACCOUNT_FAILURES", 5),
+		LoginIPFailures:           getEnvInt("LOGIN_IP_FAILURES", 20),
+		LoginLockoutBase:          getEnvDuration("LOGIN_LOCKOUT_BASE", 30*time.Second),
+		LoginLockoutMax:           getEnvDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+		LoginFailureWindow:        getEnvDuration("LOGIN_FAILURE_WINDOW", 24*time.Hour),
+		TrustedProxies:            getEnvList("TRUSTED_PROXIES", "127.0.0.1,::1"),
+		PasswordHash:              getEnv("PASSWORD_HASH", "argon2id"),
+		BcryptCost:                getEnvInt("BCRYPT_COST", 12),
+		Argon2Memory:              getEnvInt("ARGON2_MEMORY", 19*1024),
+		Argon2Iterations:          getEnvInt("ARGON2_ITERATIONS", 2),
+		Argon2Parallelism:         getEnvInt("ARGON2_PARALLELISM", 1),
+		PasswordMinLength:         getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMaxLength:         getEnvInt("PASSWORD_MAX_LENGTH", 72),
+		PasswordBlocklist:         getEnvList("PASSWORD_BLOCKLIST", ""),
+	}
+}
+
+var oidcProviderID = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// loadOIDCProviders reads the providers listed in OIDC_PROVIDERS, e.g. "company"
+// with OIDC_COMPANY_ISSUER, OIDC_COMPANY_CLIENT_ID, OIDC_COMPANY_CLIENT_SECRET and
+// optionally OIDC_COMPANY_NAME and OIDC_COMPANY_SCOPES.
+func loadOIDCProviders() []OIDCProvider {
+	var providers []OIDCProvider
+	for _, id := range getEnvList("OIDC_PROVIDERS", "") {
+		if !oidcProviderID.MatchString(id) {
+			log.Printf("Warning: invalid OIDC provider id %q, skipping it", id)
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(id, "-", "_")) + "_"
+		provider := OIDCProvider{
+			ID:           id,
+			Name:         getEnv(prefix+"NAME", id),
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       getEnvList(prefix+"SCOPES", "openid,email,profile"),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			log.Printf("Warning: %sISSUER and %sCLIENT_ID are required, skipping OIDC provider %q", prefix, prefix, id)
+			continue
+		}
+		providers = append(providers, provider)
 	}
+	return providers
 }
 
 // getEnv retrieves environment variables or provides a fallback default.
@@ -53,3 +200,56 @@ func getEnv(key string, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList retrieves a comma-separated environment variable as a list, skipping empty items.
+func getEnvList(key string, defaultValue string) []string {
+	var items []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvBool retrieves an environment variable such as "true" or "0" as a boolean.
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid boolean %q in %s, using %t", value, key, defaultValue)
+		return defaultValue
+	}
+	return b
+}
+
+// getEnvInt retrieves an environment variable such as "5" as an integer.
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid integer %q in %s, using %d", value, key, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getEnvDuration retrieves an environment variable such as "48h" as a duration.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid duration %q in %s, using %s", value, key, defaultValue)
+		return defaultValue
+	}
+	return d
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+	"programming_blog_go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// actorFromContext builds the use case actor from the access token claims the auth
+// middleware verified. Requests that got past no auth middleware are unauthorized.
+func actorFromContext(c *gin.Context) (usecase.Actor, error) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		return usecase.Actor{}, domain.ErrUnauthorized
+	}
+	return usecase.Actor{UserID: userID, Role: utils.GetRoleFromContext(c), Scopes: utils.GetScopesFromContext(c)}, nil
+}
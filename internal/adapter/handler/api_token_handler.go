@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APITokenHandler handles the logged-in user's personal API tokens.
+type APITokenHandler struct {
+	CreateAPITokenUseCase *usecase.CreateAPITokenUseCase
+	ListAPITokensUseCase  *usecase.ListAPITokensUseCase
+	RevokeAPITokenUseCase *usecase.RevokeAPITokenUseCase
+}
+
+// NewAPITokenHandler creates a new APITokenHandler.
+func NewAPITokenHandler(
+	createUC *usecase.CreateAPITokenUseCase,
+	listUC *usecase.ListAPITokensUseCase,
+	revokeUC *usecase.RevokeAPITokenUseCase,
+) *APITokenHandler {
+	return &APITokenHandler{
+		CreateAPITokenUseCase: createUC,
+		ListAPITokensUseCase:  listUC,
+		RevokeAPITokenUseCase: revokeUC,
+	}
+}
+
+// CreateAPIToken handles creating a token; the response is the only time its value is shown.
+func (h *APITokenHandler) CreateAPIToken(c *gin.Context) {
+	var req usecase.CreateAPITokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return
+	}
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	created, err := h.CreateAPITokenUseCase.Execute(actor, req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListAPITokens handles listing the user's tokens.
+func (h *APITokenHandler) ListAPITokens(c *gin.Context) {
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	tokens, err := h.ListAPITokensUseCase.Execute(actor)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// RevokeAPIToken handles deleting one of the user's tokens.
+func (h *APITokenHandler) RevokeAPIToken(c *gin.Context) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	if err := h.RevokeAPITokenUseCase.Execute(actor, id); err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
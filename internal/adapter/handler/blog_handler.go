@@ -8,52 +8,123 @@ import (
 	"programming_blog_go/internal/usecase"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // BlogHandler handles HTTP requests related to blog posts and categories.
 type BlogHandler struct {
 	GetBlogPostsUseCase           *usecase.GetBlogPostsUseCase
 	GetBlogPostsByCategoryUseCase *usecase.GetBlogPostsByCategoryUseCase
+	GetBlogPostsByTagUseCase      *usecase.GetBlogPostsByTagUseCase
 	GetBlogPostBySlugUseCase      *usecase.GetBlogPostBySlugUseCase
+	GetBlogPostByIDUseCase        *usecase.GetBlogPostByIDUseCase
 	CreateBlogPostUseCase         *usecase.CreateBlogPostUseCase
+	UpdateBlogPostUseCase         *usecase.UpdateBlogPostUseCase
+	DeleteBlogPostUseCase         *usecase.DeleteBlogPostUseCase
 }
 
 // NewBlogHandler creates a new BlogHandler.
 func NewBlogHandler(
 	getBlogPostsUC *usecase.GetBlogPostsUseCase,
 	getBlogPostsByCategoryUC *usecase.GetBlogPostsByCategoryUseCase,
+	getBlogPostsByTagUC *usecase.GetBlogPostsByTagUseCase,
 	getBlogPostBySlugUC *usecase.GetBlogPostBySlugUseCase,
+	getBlogPostByIDUC *usecase.GetBlogPostByIDUseCase,
 	createBlogPostUC *usecase.CreateBlogPostUseCase,
+	updateBlogPostUC *usecase.UpdateBlogPostUseCase,
+	deleteBlogPostUC *usecase.DeleteBlogPostUseCase,
 ) *BlogHandler {
 	return &BlogHandler{
 		GetBlogPostsUseCase:           getBlogPostsUC,
 		GetBlogPostsByCategoryUseCase: getBlogPostsByCategoryUC,
+		GetBlogPostsByTagUseCase:      getBlogPostsByTagUC,
 		GetBlogPostBySlugUseCase:      getBlogPostBySlugUC,
+		GetBlogPostByIDUseCase:        getBlogPostByIDUC,
 		CreateBlogPostUseCase:         createBlogPostUC,
+		UpdateBlogPostUseCase:         updateBlogPostUC,
+		DeleteBlogPostUseCase:         deleteBlogPostUC,
 	}
 }
 
-// GetBlogPosts handles the request to get all blog posts.
+// GetBlogPosts handles the request to get a page of published blog posts.
 func (h *BlogHandler) GetBlogPosts(c *gin.Context) {
-	posts, err := h.GetBlogPostsUseCase.Execute(true)
+	pageReq, err := parsePageRequest(c)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
-	c.HTML(http.StatusOK, "index.html", gin.H{"posts": posts, "title": "Главная страница"})
+
+	page, err := h.GetBlogPostsUseCase.ExecutePage(true, pageReq)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	renderHTML(c, http.StatusOK, "index.html", gin.H{
+		"posts":     page.Posts,
+		"page":      page,
+		"base_path": c.Request.URL.Path,
+		"title":     "Главная страница",
+	})
 }
 
-// GetBlogPostsByCategory handles the request to get blog posts by category slug.
+// ListBlogPosts handles the API request to get a page of published blog posts as JSON.
+func (h *BlogHandler) ListBlogPosts(c *gin.Context) {
+	pageReq, err := parsePageRequest(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	page, err := h.GetBlogPostsUseCase.ExecutePage(true, pageReq)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetBlogPostsByCategory handles the request to get a page of blog posts by category slug.
 func (h *BlogHandler) GetBlogPostsByCategory(c *gin.Context) {
+	pageReq, err := parsePageRequest(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
 	categorySlug := c.Param("cat_slug")
-	posts, err := h.GetBlogPostsByCategoryUseCase.Execute(categorySlug, true)
+	category, page, err := h.GetBlogPostsByCategoryUseCase.ExecutePage(categorySlug, true, pageReq)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
+	renderHTML(c, http.StatusOK, "index.html", gin.H{
+		"posts":     page.Posts,
+		"page":      page,
+		"base_path": c.Request.URL.Path,
+		"title":     "Категория - " + category.Name,
+	})
+}
 
-	// TODO: Fetch category name for the title (will need a category use case)
-	c.HTML(http.StatusOK, "index.html", gin.H{"posts": posts, "title": "Категория - " + categorySlug})
+// GetBlogPostsByTag handles the request to get a page of blog posts by tag slug.
+func (h *BlogHandler) GetBlogPostsByTag(c *gin.Context) {
+	pageReq, err := parsePageRequest(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	tagSlug := c.Param("tag_slug")
+	tag, page, err := h.GetBlogPostsByTagUseCase.Execute(tagSlug, true, pageReq)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	renderHTML(c, http.StatusOK, "index.html", gin.H{
+		"posts":     page.Posts,
+		"page":      page,
+		"base_path": c.Request.URL.Path,
+		"title":     "Тег - " + tag.Name,
+	})
 }
 
 // GetBlogPost handles the request to get a single blog post by slug.
@@ -69,7 +140,7 @@ func (h *BlogHandler) GetBlogPost(c *gin.Context) {
 	// 	c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 	// 	return
 	// }
-	c.HTML(http.StatusOK, "post.html", gin.H{"post": post, "title": post.Title})
+	renderHTML(c, http.StatusOK, "post.html", gin.H{"post": post, "title": post.Title})
 }
 
 // CreateBlogPost handles the request to create a new blog post.
@@ -80,17 +151,94 @@ func (h *BlogHandler) CreateBlogPost(c *gin.Context) {
 		return
 	}
 
-	blog, err := h.CreateBlogPostUseCase.Execute(req)
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	blog, err := h.CreateBlogPostUseCase.Execute(req, actor)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
+	// The add post form gets the new post's page rather than JSON
+	if c.ContentType() == binding.MIMEPOSTForm || c.ContentType() == binding.MIMEMultipartPOSTForm {
+		c.Redirect(http.StatusSeeOther, "/post/"+blog.Slug)
+		return
+	}
 	c.JSON(http.StatusCreated, blog)
 }
 
+// GetBlogPostByID handles the API request to get a single blog post by ID.
+func (h *BlogHandler) GetBlogPostByID(c *gin.Context) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	blog, err := h.GetBlogPostByIDUseCase.Execute(id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, blog)
+}
+
+// UpdateBlogPost handles PUT and PATCH requests for an existing blog post.
+// Only the fields present in the request body are changed.
+func (h *BlogHandler) UpdateBlogPost(c *gin.Context) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	var req usecase.UpdateBlogPostRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	blog, err := h.UpdateBlogPostUseCase.Execute(id, req, actor)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, blog)
+}
+
+// DeleteBlogPost handles the request to delete a blog post.
+func (h *BlogHandler) DeleteBlogPost(c *gin.Context) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	if err := h.DeleteBlogPostUseCase.Execute(id, actor); err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
 // AddPostPage renders the form for adding a new post.
 func (h *BlogHandler) AddPostPage(c *gin.Context) {
-	c.HTML(http.StatusOK, "addpage.html", gin.H{"title": "Добавление статьи"})
+	renderHTML(c, http.StatusOK, "addpage.html", gin.H{"title": "Добавление статьи"})
 }
 
 // Temporary solution to get categories for layout
@@ -111,3 +259,33 @@ func (h *BlogHandler) GetCategoryIDFromName(c *gin.Context) {
 	id, _ := strconv.Atoi(categoryName) // In a real scenario, this would be a lookup
 	c.JSON(http.StatusOK, gin.H{"id": id})
 }
+
+// parseIDParam reads a numeric path parameter, mapping malformed values to domain.ErrInvalidInput.
+func parseIDParam(c *gin.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, domain.ErrInvalidInput
+	}
+	return uint(id), nil
+}
+
+// parsePageRequest reads the ?page=, ?per_page= and ?cursor= query parameters.
+func parsePageRequest(c *gin.Context) (domain.PageRequest, error) {
+	var req domain.PageRequest
+	if page := c.Query("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return req, domain.ErrInvalidInput
+		}
+		req.Page = n
+	}
+	if perPage := c.Query("per_page"); perPage != "" {
+		n, err := strconv.Atoi(perPage)
+		if err != nil || n < 1 {
+			return req, domain.ErrInvalidInput
+		}
+		req.PerPage = n
+	}
+	req.Cursor = c.Query("cursor")
+	return req, nil
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CategoryHandler handles HTTP requests related to category management.
+type CategoryHandler struct {
+	GetAllCategoriesUseCase *usecase.GetAllCategoriesUseCase
+	CreateCategoryUseCase   *usecase.CreateCategoryUseCase
+	UpdateCategoryUseCase   *usecase.UpdateCategoryUseCase
+	DeleteCategoryUseCase   *usecase.DeleteCategoryUseCase
+}
+
+// NewCategoryHandler creates a new CategoryHandler.
+func NewCategoryHandler(
+	getAllCategoriesUC *usecase.GetAllCategoriesUseCase,
+	createCategoryUC *usecase.CreateCategoryUseCase,
+	updateCategoryUC *usecase.UpdateCategoryUseCase,
+	deleteCategoryUC *usecase.DeleteCategoryUseCase,
+) *CategoryHandler {
+	return &CategoryHandler{
+		GetAllCategoriesUseCase: getAllCategoriesUC,
+		CreateCategoryUseCase:   createCategoryUC,
+		UpdateCategoryUseCase:   updateCategoryUC,
+		DeleteCategoryUseCase:   deleteCategoryUC,
+	}
+}
+
+// GetCategories handles the request to list all categories.
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	categories, err := h.GetAllCategoriesUseCase.Execute()
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// CreateCategory handles the request to create a new category.
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req usecase.CreateCategoryRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	category, err := h.CreateCategoryUseCase.Execute(req, actor)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory handles PUT and PATCH requests that rename a category or change its slug.
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	var req usecase.UpdateCategoryRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	category, err := h.UpdateCategoryUseCase.Execute(id, req, actor)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory handles the request to delete an empty category.
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	if err := h.DeleteCategoryUseCase.Execute(id, actor); err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
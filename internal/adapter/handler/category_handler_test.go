@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+	"programming_blog_go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryCategories is a CategoryRepository backed by a slice.
+type memoryCategories struct {
+	categories []domain.Category
+}
+
+func (r *memoryCategories) Create(category *domain.Category) error {
+	category.ID = uint(len(r.categories) + 1)
+	r.categories = append(r.categories, *category)
+	return nil
+}
+
+func (r *memoryCategories) FindByID(id uint) (*domain.Category, error) {
+	for i := range r.categories {
+		if r.categories[i].ID == id {
+			return &r.categories[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryCategories) FindBySlug(slug string) (*domain.Category, error) {
+	for i := range r.categories {
+		if r.categories[i].Slug == slug {
+			return &r.categories[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryCategories) FindAll() ([]domain.Category, error)    { return r.categories, nil }
+func (r *memoryCategories) Update(category *domain.Category) error { return nil }
+func (r *memoryCategories) Delete(id uint) error                   { return nil }
+
+func TestCreateCategory_DerivesSlugFromName(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantSlug   string
+	}{
+		{"slug omitted", `{"name": "Web Development"}`, http.StatusCreated, "web-development"},
+		{"empty slug", `{"name": "Go & Rust", "slug": ""}`, http.StatusCreated, "go-rust"},
+		{"explicit slug", `{"name": "Databases", "slug": "db"}`, http.StatusCreated, "db"},
+		{"invalid slug", `{"name": "Databases", "slug": "Not A Slug"}`, http.StatusBadRequest, ""},
+		{"missing name", `{"slug": "db"}`, http.StatusBadRequest, ""},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewCategoryHandler(nil, &usecase.CreateCategoryUseCase{CategoryRepository: &memoryCategories{}}, nil, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/categories", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			utils.SetAccessClaims(c, &domain.AccessClaims{UserID: 1, Username: "admin", Role: domain.RoleAdmin})
+
+			h.CreateCategory(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			var category domain.Category
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &category))
+			assert.Equal(t, tt.wantSlug, category.Slug)
+		})
+	}
+}
@@ -39,5 +39,5 @@ func (h *ContactHandler) SendContactMessage(c *gin.Context) {
 // ShowContactPage renders the contact form page.
 // This is already present as a dummy in blog_handler.go, but will be moved here.
 func (h *ContactHandler) ShowContactPage(c *gin.Context) {
-	c.HTML(http.StatusOK, "contact.html", gin.H{"title": "Обратная связь"})
+	renderHTML(c, http.StatusOK, "contact.html", gin.H{"title": "Обратная связь"})
 }
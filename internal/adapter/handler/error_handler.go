@@ -15,10 +15,46 @@ func HandleError(c *gin.Context, err error) {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 	case domain.ErrAlreadyExists:
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case domain.ErrCategoryInUse:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 	case domain.ErrInvalidInput:
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case domain.ErrUnauthorized:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case domain.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case domain.ErrEmailNotVerified:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 	case usecase.ErrInvalidCredentials:
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case usecase.ErrTooManyLoginAttempts:
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case usecase.ErrPasswordTooShort:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case usecase.ErrPasswordTooLong:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case usecase.ErrPasswordTooCommon:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case usecase.ErrInvalidVerificationToken:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case usecase.ErrInvalidResetToken:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case usecase.ErrInvalidRefreshToken:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case usecase.ErrInvalidTwoFactorCode:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case usecase.ErrInvalidTwoFactorChallenge:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case usecase.ErrTwoFactorAlreadyEnabled:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case usecase.ErrTwoFactorNotSetUp:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case usecase.ErrInvalidAPIToken:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case usecase.ErrAPITokenLimitReached:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case usecase.ErrAPITokenNotPermitted:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 	case usecase.ErrUserNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 	case usecase.ErrUserAlreadyExists:
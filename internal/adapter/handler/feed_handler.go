@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedSize is the number of most recent posts included in a feed.
+const feedSize = 20
+
+// FeedHandler serves RSS 2.0 and Atom feeds of published posts.
+type FeedHandler struct {
+	GetBlogPostsUseCase           *usecase.GetBlogPostsUseCase
+	GetBlogPostsByCategoryUseCase *usecase.GetBlogPostsByCategoryUseCase
+	BaseURL                       string // Absolute site root without a trailing slash
+	SiteTitle                     string
+}
+
+// NewFeedHandler creates a new FeedHandler.
+func NewFeedHandler(
+	getBlogPostsUC *usecase.GetBlogPostsUseCase,
+	getBlogPostsByCategoryUC *usecase.GetBlogPostsByCategoryUseCase,
+	baseURL string,
+	siteTitle string,
+) *FeedHandler {
+	return &FeedHandler{
+		GetBlogPostsUseCase:           getBlogPostsUC,
+		GetBlogPostsByCategoryUseCase: getBlogPostsByCategoryUC,
+		BaseURL:                       strings.TrimRight(baseURL, "/"),
+		SiteTitle:                     siteTitle,
+	}
+}
+
+// feed is the format-independent content of a feed.
+type feed struct {
+	Title   string
+	SiteURL string // Page the feed mirrors
+	SelfURL string // URL of the feed itself
+	Posts   []domain.Blog
+}
+
+// RSS handles the request for the RSS 2.0 feed of the whole blog.
+func (h *FeedHandler) RSS(c *gin.Context) {
+	f, err := h.blogFeed(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	h.writeRSS(c, f)
+}
+
+// Atom handles the request for the Atom feed of the whole blog.
+func (h *FeedHandler) Atom(c *gin.Context) {
+	f, err := h.blogFeed(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	h.writeAtom(c, f)
+}
+
+// CategoryRSS handles the request for the RSS 2.0 feed of a single category.
+func (h *FeedHandler) CategoryRSS(c *gin.Context) {
+	f, err := h.categoryFeed(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	h.writeRSS(c, f)
+}
+
+// CategoryAtom handles the request for the Atom feed of a single category.
+func (h *FeedHandler) CategoryAtom(c *gin.Context) {
+	f, err := h.categoryFeed(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	h.writeAtom(c, f)
+}
+
+func (h *FeedHandler) blogFeed(c *gin.Context) (*feed, error) {
+	page, err := h.GetBlogPostsUseCase.ExecutePage(true, domain.PageRequest{PerPage: feedSize})
+	if err != nil {
+		return nil, err
+	}
+	return &feed{
+		Title:   h.SiteTitle,
+		SiteURL: h.BaseURL + "/",
+		SelfURL: h.BaseURL + c.Request.URL.Path,
+		Posts:   page.Posts,
+	}, nil
+}
+
+func (h *FeedHandler) categoryFeed(c *gin.Context) (*feed, error) {
+	category, page, err := h.GetBlogPostsByCategoryUseCase.ExecutePage(c.Param("cat_slug"), true, domain.PageRequest{PerPage: feedSize})
+	if err != nil {
+		return nil, err
+	}
+	return &feed{
+		Title:   h.SiteTitle + " - " + category.Name,
+		SiteURL: h.BaseURL + "/category/" + category.Slug,
+		SelfURL: h.BaseURL + c.Request.URL.Path,
+		Posts:   page.Posts,
+	}, nil
+}
+
+// postURL returns the absolute URL of a post.
+func (h *FeedHandler) postURL(post domain.Blog) string {
+	return h.BaseURL + "/post/" + post.Slug
+}
+
+// postContentHTML returns the cached HTML of a post, falling back to the escaped
+// source for posts that have not been rendered yet.
+func postContentHTML(post domain.Blog) string {
+	if post.ContentHTML != "" {
+		return post.ContentHTML
+	}
+	return html.EscapeString(post.Content)
+}
+
+// lastModified returns the most recent update time among the feed's posts.
+func (f *feed) lastModified() time.Time {
+	var latest time.Time
+	for _, post := range f.Posts {
+		if post.TimeUpdate.After(latest) {
+			latest = post.TimeUpdate
+		}
+	}
+	return latest
+}
+
+// etag identifies the feed contents: it changes when any post in the feed is added,
+// removed, updated or rendered again by a newer renderer.
+func (f *feed) etag(format string) string {
+	hash := sha1.New()
+	fmt.Fprintf(hash, "%s|%s", format, f.Title)
+	for _, post := range f.Posts {
+		fmt.Fprintf(hash, "|%d:%d:%d", post.ID, post.TimeUpdate.UnixNano(), post.ContentHTMLVersion)
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)) + `"`
+}
+
+// notModified sets the validators on the response and reports whether the request's
+// If-None-Match or If-Modified-Since header shows the client already has this version.
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110, section 13.2.2)
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := c.GetHeader("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		// HTTP dates have second precision
+		if err == nil && !lastModified.Truncate(time.Second).After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// RSS 2.0 document structure.
+type rssDocument struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomXMLNS string     `xml:"xmlns:atom,attr"`
+	DCXMLNS   string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Author      string  `xml:"dc:creator,omitempty"`
+	Category    string  `xml:"category,omitempty"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (h *FeedHandler) writeRSS(c *gin.Context, f *feed) {
+	lastModified := f.lastModified()
+	if notModified(c, f.etag("rss"), lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.SiteURL,
+		Description: "Latest posts from " + f.Title,
+		AtomLink:    rssLink{Href: f.SelfURL, Rel: "self", Type: "application/rss+xml"},
+	}
+	if !lastModified.IsZero() {
+		channel.LastBuildDate = lastModified.UTC().Format(time.RFC1123Z)
+	}
+	for _, post := range f.Posts {
+		item := rssItem{
+			Title:       post.Title,
+			Link:        h.postURL(post),
+			GUID:        rssGUID{IsPermaLink: true, Value: h.postURL(post)},
+			PubDate:     post.TimeCreated.UTC().Format(time.RFC1123Z),
+			Description: postContentHTML(post),
+		}
+		if post.Author != nil {
+			item.Author = post.Author.Username
+		}
+		if post.Category != nil {
+			item.Category = post.Category.Name
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	writeXML(c, "application/rss+xml; charset=utf-8", rssDocument{
+		Version:   "2.0",
+		AtomXMLNS: "http://www.w3.org/2005/Atom",
+		DCXMLNS:   "http://purl.org/dc/elements/1.1/",
+		Channel:   channel,
+	})
+}
+
+// Atom (RFC 4287) document structure.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Link      atomLink    `xml:"link"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Author    *atomAuthor `xml:"author,omitempty"`
+	Category  *atomTerm   `xml:"category,omitempty"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomTerm struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (h *FeedHandler) writeAtom(c *gin.Context, f *feed) {
+	lastModified := f.lastModified()
+	if notModified(c, f.etag("atom"), lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	updated := lastModified
+	if updated.IsZero() {
+		updated = time.Now() // <updated> is mandatory even for an empty feed
+	}
+	doc := atomFeed{
+		Title:   f.Title,
+		ID:      f.SiteURL,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.SelfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.SiteURL, Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, post := range f.Posts {
+		entry := atomEntry{
+			Title:     post.Title,
+			ID:        h.postURL(post),
+			Link:      atomLink{Href: h.postURL(post), Rel: "alternate", Type: "text/html"},
+			Published: post.TimeCreated.UTC().Format(time.RFC3339),
+			Updated:   post.TimeUpdate.UTC().Format(time.RFC3339),
+			Content:   atomContent{Type: "html", Value: postContentHTML(post)},
+		}
+		if post.Author != nil {
+			entry.Author = &atomAuthor{Name: post.Author.Username}
+		}
+		if post.Category != nil {
+			entry.Category = &atomTerm{Term: post.Category.Slug, Label: post.Category.Name}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	writeXML(c, "application/atom+xml; charset=utf-8", doc)
+}
+
+// writeXML marshals the document with an XML declaration.
+func writeXML(c *gin.Context, contentType string, doc interface{}) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, contentType, append([]byte(xml.Header), body...))
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"programming_blog_go/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFeedTestContext(headers map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/feed.xml", nil)
+	for k, v := range headers {
+		c.Request.Header.Set(k, v)
+	}
+	return c, w
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	etag := `W/"abc"`
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{"no validators", nil, false},
+		{"matching etag", map[string]string{"If-None-Match": `W/"abc"`}, true},
+		{"strong form of weak etag", map[string]string{"If-None-Match": `"old", "abc"`}, true},
+		{"wildcard", map[string]string{"If-None-Match": "*"}, true},
+		{"stale etag", map[string]string{"If-None-Match": `W/"old"`}, false},
+		{"etag wins over date", map[string]string{"If-None-Match": `W/"old"`, "If-Modified-Since": lastModified.Add(time.Hour).Format(http.TimeFormat)}, false},
+		{"same second", map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, true},
+		{"older copy", map[string]string{"If-Modified-Since": lastModified.Add(-time.Minute).Format(http.TimeFormat)}, false},
+		{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newFeedTestContext(tt.headers)
+			assert.Equal(t, tt.want, notModified(c, etag, lastModified))
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Equal(t, "Wed, 01 May 2024 12:00:00 GMT", w.Header().Get("Last-Modified"))
+		})
+	}
+}
+
+func TestFeedETag_ChangesWhenRenderedAgain(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	f := &feed{Title: "Blog", Posts: []domain.Blog{{ID: 1, TimeUpdate: updated, ContentHTMLVersion: 1}}}
+	before := f.etag("rss")
+
+	f.Posts[0].ContentHTMLVersion = 2
+	assert.NotEqual(t, before, f.etag("rss"))
+	assert.NotEqual(t, f.etag("rss"), f.etag("atom"))
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+
+	"programming_blog_go/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler publishes the public keys access tokens are signed with, so other
+// services can verify them without sharing a secret.
+type JWKSHandler struct {
+	PublicKeys domain.PublicKeyProvider
+}
+
+// NewJWKSHandler creates a new JWKSHandler.
+func NewJWKSHandler(publicKeys domain.PublicKeyProvider) *JWKSHandler {
+	return &JWKSHandler{PublicKeys: publicKeys}
+}
+
+// JWKS serves /.well-known/jwks.json. The short cache lifetime lets verifiers
+// pick up a new key soon after a rotation.
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.PublicKeys.PublicKeys())
+}
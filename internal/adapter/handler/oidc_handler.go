@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"programming_blog_go/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcLoginCookie keeps the sealed state of an external login between the
+// redirect to the provider and its callback. It is scoped to the callback URLs.
+const (
+	oidcLoginCookie     = "oidc_login"
+	oidcLoginCookiePath = "/auth/oidc/"
+)
+
+// OIDCHandler handles logging in with an external OpenID Connect provider.
+type OIDCHandler struct {
+	StartExternalLoginUseCase    *usecase.StartExternalLoginUseCase
+	CompleteExternalLoginUseCase *usecase.CompleteExternalLoginUseCase
+	TwoFactorChallenge           *usecase.TwoFactorChallenge
+	StartSessionUseCase          *usecase.StartSessionUseCase
+	SecureCookies                bool // Send cookies over HTTPS only
+}
+
+// NewOIDCHandler creates a new OIDCHandler.
+func NewOIDCHandler(
+	startUC *usecase.StartExternalLoginUseCase,
+	completeUC *usecase.CompleteExternalLoginUseCase,
+	twoFactorChallenge *usecase.TwoFactorChallenge,
+	startSessionUC *usecase.StartSessionUseCase,
+	secureCookies bool,
+) *OIDCHandler {
+	return &OIDCHandler{
+		StartExternalLoginUseCase:    startUC,
+		CompleteExternalLoginUseCase: completeUC,
+		TwoFactorChallenge:           twoFactorChallenge,
+		StartSessionUseCase:          startSessionUC,
+		SecureCookies:                secureCookies,
+	}
+}
+
+// StartLogin handles sending the browser to the provider's login page.
+func (h *OIDCHandler) StartLogin(c *gin.Context) {
+	next := safeRedirectTarget(c.Query("next"))
+	redirect, err := h.StartExternalLoginUseCase.Execute(c.Param("provider"), next)
+	switch err {
+	case nil:
+	case usecase.ErrUnknownIdentityProvider:
+		renderExternalLoginError(c, http.StatusNotFound, next, "Unknown login provider.")
+		return
+	case usecase.ErrExternalLoginFailed:
+		renderExternalLoginError(c, http.StatusBadGateway, next, "The login provider is not available right now.")
+		return
+	default:
+		HandleError(c, err)
+		return
+	}
+
+	h.setLoginCookie(c, redirect.State, redirect.StateTTL)
+	c.Redirect(http.StatusFound, redirect.URL)
+}
+
+// Callback handles the provider sending the browser back after the login.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	sealed, _ := c.Cookie(oidcLoginCookie)
+	h.setLoginCookie(c, "", 0) // Each started login can be completed once
+
+	var req usecase.CompleteExternalLoginRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderExternalLoginError(c, http.StatusBadRequest, "/", "Invalid login response.")
+		return
+	}
+
+	user, next, err := h.CompleteExternalLoginUseCase.Execute(c.Param("provider"), req, sealed)
+	switch err {
+	case nil:
+	case usecase.ErrUnknownIdentityProvider:
+		renderExternalLoginError(c, http.StatusNotFound, "/", "Unknown login provider.")
+		return
+	case usecase.ErrInvalidExternalLogin:
+		renderExternalLoginError(c, http.StatusBadRequest, "/", "The login has expired, please start again.")
+		return
+	case usecase.ErrExternalLoginFailed:
+		renderExternalLoginError(c, http.StatusUnauthorized, "/", "The login provider did not log you in.")
+		return
+	case usecase.ErrExternalEmailNotVerified:
+		renderExternalLoginError(c, http.StatusForbidden, "/", "The login provider has not confirmed your email address.")
+		return
+	case usecase.ErrExternalSignupDisabled:
+		renderExternalLoginError(c, http.StatusForbidden, "/", "There is no account with your email address.")
+		return
+	case usecase.ErrExternalLinkNotAllowed:
+		renderExternalLoginError(c, http.StatusConflict, "/",
+			"An account with your email address exists. Log in with its password and confirm the address first.")
+		return
+	default:
+		HandleError(c, err)
+		return
+	}
+
+	next = safeRedirectTarget(next)
+	// The provider's login does not replace the blog's own second factor
+	if user.IsTwoFactorEnabled() {
+		challenge, _ := h.TwoFactorChallenge.Token(user, time.Now())
+		renderTwoFactorLoginPage(c, http.StatusOK, challenge, next, "")
+		return
+	}
+	startBrowserSession(c, h.StartSessionUseCase, user, next, h.SecureCookies)
+}
+
+func (h *OIDCHandler) setLoginCookie(c *gin.Context, value string, ttl time.Duration) {
+	cookie := &http.Cookie{
+		Name:     oidcLoginCookie,
+		Value:    value,
+		Path:     oidcLoginCookiePath,
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   h.SecureCookies,
+		// Lax, so the cookie comes along when the provider redirects back
+		SameSite: http.SameSiteLaxMode,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+func renderExternalLoginError(c *gin.Context, status int, next, message string) {
+	renderHTML(c, status, "login.html", gin.H{"title": "Авторизация", "next": next, "error": message})
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordResetHandler handles the "forgot password" flow.
+type PasswordResetHandler struct {
+	ForgotPasswordUseCase *usecase.ForgotPasswordUseCase
+	ResetPasswordUseCase  *usecase.ResetPasswordUseCase
+}
+
+// NewPasswordResetHandler creates a new PasswordResetHandler.
+func NewPasswordResetHandler(
+	forgotPasswordUC *usecase.ForgotPasswordUseCase,
+	resetPasswordUC *usecase.ResetPasswordUseCase,
+) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		ForgotPasswordUseCase: forgotPasswordUC,
+		ResetPasswordUseCase:  resetPasswordUC,
+	}
+}
+
+// ShowForgotPasswordPage renders the form asking for the account's email address.
+func (h *PasswordResetHandler) ShowForgotPasswordPage(c *gin.Context) {
+	renderHTML(c, http.StatusOK, "forgot_password.html", gin.H{"title": "Восстановление пароля"})
+}
+
+// ForgotPassword handles a request for a password reset link.
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var req usecase.ForgotPasswordRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return
+	}
+	if err := h.ForgotPasswordUseCase.Execute(req); err != nil {
+		HandleError(c, err)
+		return
+	}
+	// Same answer for unknown addresses
+	c.JSON(http.StatusAccepted, gin.H{"message": "If an account uses this address, a reset link is on its way."})
+}
+
+// ShowResetPasswordPage renders the form for choosing a new password from an emailed link.
+func (h *PasswordResetHandler) ShowResetPasswordPage(c *gin.Context) {
+	renderHTML(c, http.StatusOK, "reset_password.html", gin.H{"title": "Новый пароль", "token": c.Query("token")})
+}
+
+// ResetPassword handles setting a new password with a reset token.
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req usecase.ResetPasswordRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return
+	}
+	if err := h.ResetPasswordUseCase.Execute(req); err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed. Please log in again."})
+}
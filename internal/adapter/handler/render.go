@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// layoutContextKeys are the values middlewares put into the Gin context for base.html.
+// "current_user" holds the logged-in visitor's access claims, "csrf_token" the
+// token forms embed with csrfField and "identity_providers" the external logins.
+var layoutContextKeys = []string{"categories", "tags", "current_user", "csrf_token", "identity_providers"}
+
+// renderHTML renders a page template, adding the shared layout data from the context
+// so every page gets the navigation without each handler passing it explicitly.
+func renderHTML(c *gin.Context, status int, name string, data gin.H) {
+	if data == nil {
+		data = gin.H{}
+	}
+	for _, key := range layoutContextKeys {
+		if _, set := data[key]; set {
+			continue
+		}
+		if value, exists := c.Get(key); exists {
+			data[key] = value
+		}
+	}
+	c.HTML(status, name, data)
+}
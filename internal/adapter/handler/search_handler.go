@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"programming_blog_go/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler handles HTTP requests related to full-text search.
+type SearchHandler struct {
+	SearchPostsUseCase *usecase.SearchPostsUseCase
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(searchPostsUC *usecase.SearchPostsUseCase) *SearchHandler {
+	return &SearchHandler{SearchPostsUseCase: searchPostsUC}
+}
+
+// SearchPage renders the search form and, when ?q= is given, the ranked results.
+func (h *SearchHandler) SearchPage(c *gin.Context) {
+	query := c.Query("q")
+	data := gin.H{"title": "Поиск", "query": query}
+	if query == "" {
+		renderHTML(c, http.StatusOK, "search.html", data)
+		return
+	}
+
+	pageReq, err := parsePageRequest(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	results, err := h.SearchPostsUseCase.Execute(query, pageReq)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	data["results"] = results
+	data["title"] = "Поиск - " + results.Query
+	renderHTML(c, http.StatusOK, "search.html", data)
+}
+
+// Search handles the API request to search posts and returns ranked results as JSON.
+func (h *SearchHandler) Search(c *gin.Context) {
+	pageReq, err := parsePageRequest(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	results, err := h.SearchPostsUseCase.Execute(c.Query("q"), pageReq)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
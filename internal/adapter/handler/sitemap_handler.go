@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SitemapHandler serves the XML sitemap and robots.txt.
+type SitemapHandler struct {
+	GetSitemapUseCase *usecase.GetSitemapUseCase
+	BaseURL           string   // Absolute site root without a trailing slash
+	RobotsDisallow    []string // Path prefixes crawlers are asked to skip
+}
+
+// NewSitemapHandler creates a new SitemapHandler.
+func NewSitemapHandler(getSitemapUC *usecase.GetSitemapUseCase, baseURL string, robotsDisallow []string) *SitemapHandler {
+	return &SitemapHandler{
+		GetSitemapUseCase: getSitemapUC,
+		BaseURL:           strings.TrimRight(baseURL, "/"),
+		RobotsDisallow:    robotsDisallow,
+	}
+}
+
+// Sitemap (https://www.sitemaps.org/protocol.html) document structure.
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+// Sitemap handles the request for /sitemap.xml. Small sites get the URLs directly;
+// past the per-file limit it becomes an index of the numbered parts.
+func (h *SitemapHandler) Sitemap(c *gin.Context) {
+	parts, err := h.GetSitemapUseCase.Parts()
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	if parts <= 1 {
+		h.writeURLSet(c, 1)
+		return
+	}
+
+	index := sitemapIndex{}
+	for part := 1; part <= parts; part++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapURL{Loc: h.BaseURL + "/sitemap-" + strconv.Itoa(part) + ".xml"})
+	}
+	writeXML(c, "application/xml; charset=utf-8", index)
+}
+
+// SitemapPart handles the request for one numbered part, e.g. /sitemap-2.xml.
+func (h *SitemapHandler) SitemapPart(c *gin.Context) {
+	name, ok := strings.CutSuffix(c.Param("part"), ".xml")
+	part, err := strconv.Atoi(name)
+	if !ok || err != nil {
+		HandleError(c, domain.ErrNotFound)
+		return
+	}
+	h.writeURLSet(c, part)
+}
+
+func (h *SitemapHandler) writeURLSet(c *gin.Context, part int) {
+	entries, err := h.GetSitemapUseCase.Execute(part)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	urlSet := sitemapURLSet{URLs: make([]sitemapURL, 0, len(entries))}
+	for _, entry := range entries {
+		u := sitemapURL{Loc: h.BaseURL + entry.Path}
+		if !entry.LastMod.IsZero() {
+			u.LastMod = entry.LastMod.UTC().Format(time.RFC3339)
+		}
+		urlSet.URLs = append(urlSet.URLs, u)
+	}
+	writeXML(c, "application/xml; charset=utf-8", urlSet)
+}
+
+// Robots handles the request for /robots.txt.
+func (h *SitemapHandler) Robots(c *gin.Context) {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if len(h.RobotsDisallow) == 0 {
+		b.WriteString("Disallow:\n") // An empty rule allows everything
+	}
+	for _, path := range h.RobotsDisallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	b.WriteString("\nSitemap: " + h.BaseURL + "/sitemap.xml\n")
+	c.String(http.StatusOK, b.String())
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/utils"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// layoutTemplate is the shared page skeleton every page template plugs its "content" block into.
+const layoutTemplate = "base.html"
+
+// templateFuncs are the helper functions available to every page template.
+var templateFuncs = template.FuncMap{
+	// renderedContent marks a post's cached HTML as safe. The HTML is sanitized
+	// by the content renderer before it is stored, never by the template.
+	"renderedContent": func(post *domain.Blog) template.HTML {
+		return template.HTML(post.ContentHTML)
+	},
+	// searchSnippet marks a search snippet as safe. The search use case escapes
+	// the snippet and only adds the <mark> tags around matches.
+	"searchSnippet": func(result domain.SearchResult) template.HTML {
+		return template.HTML(result.SnippetHTML)
+	},
+	// defaultPageSize lets pagination links leave out ?per_page= unless the
+	// visitor asked for another size.
+	"defaultPageSize": func() int {
+		return domain.DefaultPageSize
+	},
+	// csrfField renders the hidden input every POST form needs, e.g.
+	// {{ csrfField $.csrf_token }}; see middleware.CSRFMiddleware.
+	"csrfField": func(token string) template.HTML {
+		return template.HTML(`<input type="hidden" name="` + utils.CSRFFormField + `" value="` + template.HTMLEscapeString(token) + `">`)
+	},
+}
+
+// HTMLTemplates renders page templates inside the shared base layout.
+// Every page defines its own "content" block, so each page is parsed into a
+// separate template set instead of one global set where the blocks would collide.
+type HTMLTemplates struct {
+	templates map[string]*template.Template
+}
+
+// LoadHTMLTemplates parses base.html together with every other *.html file in dir.
+func LoadHTMLTemplates(dir string) (*HTMLTemplates, error) {
+	layout := filepath.Join(dir, layoutTemplate)
+	pages, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]*template.Template, len(pages))
+	for _, page := range pages {
+		name := filepath.Base(page)
+		if name == layoutTemplate {
+			continue
+		}
+		tmpl, err := template.New(layoutTemplate).Funcs(templateFuncs).ParseFiles(layout, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+		templates[name] = tmpl
+	}
+	return &HTMLTemplates{templates: templates}, nil
+}
+
+// Instance implements render.HTMLRender.
+func (t *HTMLTemplates) Instance(name string, data any) render.Render {
+	return render.HTML{
+		Template: t.templates[name],
+		Name:     layoutTemplate,
+		Data:     data,
+	}
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"programming_blog_go/internal/domain"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderTestTemplate(t *testing.T, name string, data gin.H) string {
+	templates, err := LoadHTMLTemplates("../../../web/templates")
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	require.NoError(t, templates.Instance(name, data).Render(w))
+	return w.Body.String()
+}
+
+func TestIndexTemplate_PaginationKeepsPageSize(t *testing.T) {
+	// Test case: A custom page size is carried to the neighbours
+	body := renderTestTemplate(t, "index.html", gin.H{
+		"base_path": "/",
+		"page":      &domain.BlogPage{Page: 2, PerPage: 5, TotalPages: 3, PrevPage: 1, NextPage: 3},
+	})
+	assert.Contains(t, body, `href="/?page=1&per_page=5"`)
+	assert.Contains(t, body, `href="/?page=3&per_page=5"`)
+
+	// Test case: Cursor pages too
+	body = renderTestTemplate(t, "index.html", gin.H{
+		"base_path": "/tag/go",
+		"page":      &domain.BlogPage{PerPage: 5, NextCursor: "abc"},
+	})
+	assert.Contains(t, body, `href="/tag/go?cursor=abc&per_page=5"`)
+
+	// Test case: The default size stays out of the URL
+	body = renderTestTemplate(t, "index.html", gin.H{
+		"base_path": "/",
+		"page":      &domain.BlogPage{Page: 1, PerPage: domain.DefaultPageSize, TotalPages: 2, NextPage: 2},
+	})
+	assert.Contains(t, body, `href="/?page=2"`)
+}
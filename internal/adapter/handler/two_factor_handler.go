@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"net/http"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler handles TOTP enrollment and the second login step.
+type TwoFactorHandler struct {
+	GetTwoFactorStatusUseCase      *usecase.GetTwoFactorStatusUseCase
+	SetupTwoFactorUseCase          *usecase.SetupTwoFactorUseCase
+	ConfirmTwoFactorUseCase        *usecase.ConfirmTwoFactorUseCase
+	DisableTwoFactorUseCase        *usecase.DisableTwoFactorUseCase
+	RegenerateRecoveryCodesUseCase *usecase.RegenerateRecoveryCodesUseCase
+	VerifyTwoFactorLoginUseCase    *usecase.VerifyTwoFactorLoginUseCase
+	StartSessionUseCase            *usecase.StartSessionUseCase
+	SecureCookies                  bool // Send session cookies over HTTPS only
+}
+
+// NewTwoFactorHandler creates a new TwoFactorHandler.
+func NewTwoFactorHandler(
+	statusUC *usecase.GetTwoFactorStatusUseCase,
+	setupUC *usecase.SetupTwoFactorUseCase,
+	confirmUC *usecase.ConfirmTwoFactorUseCase,
+	disableUC *usecase.DisableTwoFactorUseCase,
+	regenerateUC *usecase.RegenerateRecoveryCodesUseCase,
+	verifyLoginUC *usecase.VerifyTwoFactorLoginUseCase,
+	startSessionUC *usecase.StartSessionUseCase,
+	secureCookies bool,
+) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		GetTwoFactorStatusUseCase:      statusUC,
+		SetupTwoFactorUseCase:          setupUC,
+		ConfirmTwoFactorUseCase:        confirmUC,
+		DisableTwoFactorUseCase:        disableUC,
+		RegenerateRecoveryCodesUseCase: regenerateUC,
+		VerifyTwoFactorLoginUseCase:    verifyLoginUC,
+		StartSessionUseCase:            startSessionUC,
+		SecureCookies:                  secureCookies,
+	}
+}
+
+// Status handles showing the logged-in user whether two-factor authentication is on.
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	status, err := h.GetTwoFactorStatusUseCase.Execute(actor)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Setup handles starting TOTP enrollment for the logged-in user.
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	setup, err := h.SetupTwoFactorUseCase.Execute(actor)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, setup)
+}
+
+// Confirm handles finishing enrollment with a first code and returns the recovery codes.
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	actor, req, ok := h.bindCodeRequest(c)
+	if !ok {
+		return
+	}
+	codes, err := h.ConfirmTwoFactorUseCase.Execute(actor, req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Disable handles turning two-factor authentication off.
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	actor, req, ok := h.bindCodeRequest(c)
+	if !ok {
+		return
+	}
+	if err := h.DisableTwoFactorUseCase.Execute(actor, req); err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes handles replacing the recovery codes.
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	actor, req, ok := h.bindCodeRequest(c)
+	if !ok {
+		return
+	}
+	codes, err := h.RegenerateRecoveryCodesUseCase.Execute(actor, req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func (h *TwoFactorHandler) bindCodeRequest(c *gin.Context) (usecase.Actor, usecase.TwoFactorCodeRequest, bool) {
+	var req usecase.TwoFactorCodeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return usecase.Actor{}, req, false
+	}
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return usecase.Actor{}, req, false
+	}
+	req.ClientIP = c.ClientIP()
+	return actor, req, true
+}
+
+// VerifyLogin handles the second login step for API clients and returns the token pair.
+func (h *TwoFactorHandler) VerifyLogin(c *gin.Context) {
+	var req usecase.VerifyTwoFactorLoginRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return
+	}
+	req.ClientIP = c.ClientIP()
+	user, err := h.VerifyTwoFactorLoginUseCase.Execute(req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	tokens, err := h.StartSessionUseCase.Execute(user)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// VerifyLoginForm handles the second step of the browser login form.
+func (h *TwoFactorHandler) VerifyLoginForm(c *gin.Context) {
+	next := safeRedirectTarget(c.PostForm("next"))
+	var req usecase.VerifyTwoFactorLoginRequest
+	if err := c.ShouldBind(&req); err != nil {
+		renderTwoFactorLoginPage(c, http.StatusBadRequest, c.PostForm("challenge_token"), next, "Enter the code from your authenticator app.")
+		return
+	}
+	req.ClientIP = c.ClientIP()
+
+	user, err := h.VerifyTwoFactorLoginUseCase.Execute(req)
+	switch err {
+	case nil:
+	case usecase.ErrInvalidTwoFactorCode:
+		renderTwoFactorLoginPage(c, http.StatusUnauthorized, req.ChallengeToken, next, "Invalid code.")
+		return
+	case usecase.ErrTooManyLoginAttempts:
+		renderTwoFactorLoginPage(c, http.StatusTooManyRequests, req.ChallengeToken, next, "Too many failed attempts. Please try again later.")
+		return
+	case usecase.ErrInvalidTwoFactorChallenge:
+		renderHTML(c, http.StatusUnauthorized, "login.html", gin.H{"title": "Авторизация", "next": next, "error": "The login has expired, please start again."})
+		return
+	default:
+		HandleError(c, err)
+		return
+	}
+
+	startBrowserSession(c, h.StartSessionUseCase, user, next, h.SecureCookies)
+}
+
+// renderTwoFactorLoginPage renders the form asking for the second factor.
+func renderTwoFactorLoginPage(c *gin.Context, status int, challengeToken, next, message string) {
+	renderHTML(c, status, "login_2fa.html", gin.H{
+		"title":           "Двухфакторная аутентификация",
+		"challenge_token": challengeToken,
+		"next":            next,
+		"error":           message,
+	})
+}
@@ -2,33 +2,57 @@ package handler
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"programming_blog_go/internal/domain"
 	"programming_blog_go/internal/usecase"
+	"programming_blog_go/internal/utils"
 
-	"time"
-
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
-// TODO: Move this to config
-var jwtSecret = []byte("your_secret_key")
-
 // UserHandler handles HTTP requests related to users.
 type UserHandler struct {
 	RegisterUserUseCase     *usecase.RegisterUserUseCase
 	AuthenticateUserUseCase *usecase.AuthenticateUserUseCase
+	UpdateUserRoleUseCase   *usecase.UpdateUserRoleUseCase
+	UnlockLoginUseCase      *usecase.UnlockLoginUseCase
+	VerifyEmailUseCase      *usecase.VerifyEmailUseCase
+	ResendVerificationEmail *usecase.ResendVerificationEmailUseCase
+	StartSessionUseCase     *usecase.StartSessionUseCase
+	RefreshSessionUseCase   *usecase.RefreshSessionUseCase
+	EndSessionUseCase       *usecase.EndSessionUseCase
+	TwoFactorChallenge      *usecase.TwoFactorChallenge
+	SecureCookies           bool // Send session cookies over HTTPS only
 }
 
 // NewUserHandler creates a new UserHandler.
 func NewUserHandler(
 	registerUserUC *usecase.RegisterUserUseCase,
 	authenticateUserUC *usecase.AuthenticateUserUseCase,
+	updateUserRoleUC *usecase.UpdateUserRoleUseCase,
+	unlockLoginUC *usecase.UnlockLoginUseCase,
+	verifyEmailUC *usecase.VerifyEmailUseCase,
+	resendVerificationEmailUC *usecase.ResendVerificationEmailUseCase,
+	startSessionUC *usecase.StartSessionUseCase,
+	refreshSessionUC *usecase.RefreshSessionUseCase,
+	endSessionUC *usecase.EndSessionUseCase,
+	twoFactorChallenge *usecase.TwoFactorChallenge,
+	secureCookies bool,
 ) *UserHandler {
 	return &UserHandler{
 		RegisterUserUseCase:     registerUserUC,
 		AuthenticateUserUseCase: authenticateUserUC,
+		UpdateUserRoleUseCase:   updateUserRoleUC,
+		UnlockLoginUseCase:      unlockLoginUC,
+		VerifyEmailUseCase:      verifyEmailUC,
+		ResendVerificationEmail: resendVerificationEmailUC,
+		StartSessionUseCase:     startSessionUC,
+		RefreshSessionUseCase:   refreshSessionUC,
+		EndSessionUseCase:       endSessionUC,
+		TwoFactorChallenge:      twoFactorChallenge,
+		SecureCookies:           secureCookies,
 	}
 }
 
@@ -46,7 +70,10 @@ func (h *UserHandler) RegisterUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully", "user_id": user.ID})
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User registered successfully. Check your email to confirm the address.",
+		"user_id": user.ID,
+	})
 }
 
 // LoginUser handles user login and generates a JWT token.
@@ -56,6 +83,7 @@ func (h *UserHandler) LoginUser(c *gin.Context) {
 		HandleError(c, domain.ErrInvalidInput)
 		return
 	}
+	req.ClientIP = c.ClientIP()
 
 	user, err := h.AuthenticateUserUseCase.Execute(req)
 	if err != nil {
@@ -63,28 +91,248 @@ func (h *UserHandler) LoginUser(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":  user.ID,
-		"username": user.Username,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(), // Token expires in 24 hours
-	})
+	// With two-factor authentication the password only earns a challenge for
+	// POST /api/login/2fa
+	if user.IsTwoFactorEnabled() {
+		challenge, expiresAt := h.TwoFactorChallenge.Token(user, time.Now())
+		c.JSON(http.StatusOK, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+			"expires_at":          expiresAt,
+		})
+		return
+	}
+
+	tokens, err := h.StartSessionUseCase.Execute(user)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// LoginForm handles the browser login form: the session is kept in cookies and the
+// visitor is sent back to the page that asked them to log in.
+func (h *UserHandler) LoginForm(c *gin.Context) {
+	next := safeRedirectTarget(c.PostForm("next"))
+	data := gin.H{"title": "Авторизация", "next": next, "username": c.PostForm("username")}
+
+	var req usecase.AuthenticateUserRequest
+	if err := c.ShouldBind(&req); err != nil {
+		data["error"] = "Enter your username and password."
+		renderHTML(c, http.StatusBadRequest, "login.html", data)
+		return
+	}
+	req.ClientIP = c.ClientIP()
+
+	user, err := h.AuthenticateUserUseCase.Execute(req)
+	switch err {
+	case nil:
+	case usecase.ErrInvalidCredentials:
+		data["error"] = "Invalid username or password."
+		renderHTML(c, http.StatusUnauthorized, "login.html", data)
+		return
+	case usecase.ErrTooManyLoginAttempts:
+		data["error"] = "Too many failed attempts. Please try again later."
+		renderHTML(c, http.StatusTooManyRequests, "login.html", data)
+		return
+	case domain.ErrEmailNotVerified:
+		data["error"] = "Please confirm your email address first."
+		renderHTML(c, http.StatusForbidden, "login.html", data)
+		return
+	default:
+		HandleError(c, err)
+		return
+	}
+
+	if user.IsTwoFactorEnabled() {
+		challenge, _ := h.TwoFactorChallenge.Token(user, time.Now())
+		renderTwoFactorLoginPage(c, http.StatusOK, challenge, next, "")
+		return
+	}
+
+	startBrowserSession(c, h.StartSessionUseCase, user, next, h.SecureCookies)
+}
+
+// startBrowserSession logs the user in with session cookies and sends the browser on to next.
+func startBrowserSession(c *gin.Context, startSession *usecase.StartSessionUseCase, user *domain.User, next string, secure bool) {
+	tokens, err := startSession.Execute(user)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	utils.SetSessionCookies(c, tokens, secure)
+	// A new session gets a new CSRF token, so one planted before login is useless
+	if _, err := utils.IssueCSRFToken(c, secure); err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.Redirect(http.StatusSeeOther, next)
+}
+
+// safeRedirectTarget only allows paths on this site, so the login form cannot be
+// used to send visitors elsewhere.
+func safeRedirectTarget(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
 
-	tokenString, err := token.SignedString(jwtSecret)
+// RefreshToken handles trading a refresh token for a new token pair.
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req usecase.RefreshSessionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return
+	}
+
+	tokens, err := h.RefreshSessionUseCase.Execute(req)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout handles revoking the current access token and, if given, its refresh token.
+func (h *UserHandler) Logout(c *gin.Context) {
+	claims, ok := utils.GetAccessClaimsFromContext(c)
+	if !ok {
+		HandleError(c, domain.ErrUnauthorized)
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+	var req usecase.EndSessionRequest
+	// The body is optional: without a refresh token only the access token is revoked
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBind(&req); err != nil {
+			HandleError(c, domain.ErrInvalidInput)
+			return
+		}
+	}
+
+	// A browser session keeps its refresh token in a cookie
+	if req.RefreshToken == "" {
+		req.RefreshToken, _ = c.Cookie(utils.RefreshTokenCookie)
+	}
+
+	if err := h.EndSessionUseCase.Execute(claims, req); err != nil {
+		HandleError(c, err)
+		return
+	}
+	utils.ClearSessionCookies(c, h.SecureCookies)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// LogoutForm handles the logout button: the session is revoked like with the API
+// and the session cookies are dropped.
+func (h *UserHandler) LogoutForm(c *gin.Context) {
+	if claims, ok := utils.GetAccessClaimsFromContext(c); ok {
+		refreshToken, _ := c.Cookie(utils.RefreshTokenCookie)
+		if err := h.EndSessionUseCase.Execute(claims, usecase.EndSessionRequest{RefreshToken: refreshToken}); err != nil {
+			HandleError(c, err)
+			return
+		}
+	}
+	utils.ClearSessionCookies(c, h.SecureCookies)
+	if _, err := utils.IssueCSRFToken(c, h.SecureCookies); err != nil {
+		HandleError(c, err)
+		return
+	}
+	renderHTML(c, http.StatusOK, "logout.html", gin.H{"title": "Выход", "logged_out": true, "current_user": nil})
+}
+
+// UpdateUserRole handles an administrator changing a user's role.
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	userID, err := parseIDParam(c, "id")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	var req usecase.UpdateUserRoleRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	user, err := h.UpdateUserRoleUseCase.Execute(userID, req, actor)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// UnlockUser handles an administrator lifting a user's login lockout.
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	userID, err := parseIDParam(c, "id")
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	actor, err := actorFromContext(c)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	if err := h.UnlockLoginUseCase.Execute(usecase.UnlockLoginRequest{UserID: userID}, actor); err != nil {
+		HandleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// VerifyEmail handles the link from the verification email and shows the outcome.
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	user, err := h.VerifyEmailUseCase.Execute(c.Query("token"))
+	if err == usecase.ErrInvalidVerificationToken {
+		renderHTML(c, http.StatusBadRequest, "verify_email.html", gin.H{"title": "Подтверждение email", "error": err.Error()})
+		return
+	}
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	renderHTML(c, http.StatusOK, "verify_email.html", gin.H{"title": "Подтверждение email", "user": user})
+}
+
+// ResendVerification handles a request for a new verification link.
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var req usecase.ResendVerificationEmailRequest
+	if err := c.ShouldBind(&req); err != nil {
+		HandleError(c, domain.ErrInvalidInput)
+		return
+	}
+	if err := h.ResendVerificationEmail.Execute(req); err != nil {
+		HandleError(c, err)
+		return
+	}
+	// Same answer for unknown and already verified addresses
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the address belongs to an unverified account, a new link is on its way."})
 }
 
 // ShowRegisterPage renders the registration form page.
 func (h *UserHandler) ShowRegisterPage(c *gin.Context) {
-	c.HTML(http.StatusOK, "register.html", gin.H{"title": "Регистрация"})
+	renderHTML(c, http.StatusOK, "register.html", gin.H{"title": "Регистрация"})
+}
+
+// ShowLogoutPage renders the logout confirmation, or a notice if nobody is logged in.
+func (h *UserHandler) ShowLogoutPage(c *gin.Context) {
+	renderHTML(c, http.StatusOK, "logout.html", gin.H{"title": "Выход"})
 }
 
 // ShowLoginPage renders the login form page.
 func (h *UserHandler) ShowLoginPage(c *gin.Context) {
-	c.HTML(http.StatusOK, "login.html", gin.H{"title": "Авторизация"})
+	renderHTML(c, http.StatusOK, "login.html", gin.H{"title": "Авторизация", "next": safeRedirectTarget(c.Query("next"))})
 }
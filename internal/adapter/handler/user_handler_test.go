@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeRedirectTarget(t *testing.T) {
+	tests := []struct {
+		next string
+		want string
+	}{
+		{"", "/"},
+		{"/addpage", "/addpage"},
+		{"/search?q=go", "/search?q=go"},
+		{"//evil.example.com", "/"},
+		{"/\\evil.example.com", "/"},
+		{"https://evil.example.com", "/"},
+		{"addpage", "/"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, safeRedirectTarget(tt.next), tt.next)
+	}
+}
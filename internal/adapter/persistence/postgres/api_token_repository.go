@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"errors"
+	"programming_blog_go/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APITokenRepository implements domain.APITokenRepository for PostgreSQL.
+type APITokenRepository struct {
+	DB *gorm.DB
+}
+
+// NewAPITokenRepository creates a new PostgreSQL API token repository.
+func NewAPITokenRepository(db *gorm.DB) *APITokenRepository {
+	return &APITokenRepository{DB: db}
+}
+
+// Create stores a new API token.
+func (r *APITokenRepository) Create(token *domain.APIToken) error {
+	return r.DB.Create(token).Error
+}
+
+// FindByHash finds an API token by the hash of its value.
+func (r *APITokenRepository) FindByHash(tokenHash string) (*domain.APIToken, error) {
+	var token domain.APIToken
+	if err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListByUserID returns the user's tokens, newest first.
+func (r *APITokenRepository) ListByUserID(userID uint) ([]domain.APIToken, error) {
+	var tokens []domain.APIToken
+	if err := r.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Delete removes the token if it belongs to the user. Checking the owner in the
+// DELETE itself means nobody can revoke someone else's token by guessing IDs.
+func (r *APITokenRepository) Delete(id, userID uint) (bool, error) {
+	result := r.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.APIToken{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteByUserID deletes all tokens of a user.
+func (r *APITokenRepository) DeleteByUserID(userID uint) error {
+	return r.DB.Where("user_id = ?", userID).Delete(&domain.APIToken{}).Error
+}
+
+// TouchLastUsed records when the token was last used.
+func (r *APITokenRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.DB.Model(&domain.APIToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -1,10 +1,13 @@
 package postgres
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"programming_blog_go/internal/domain"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BlogRepository implements domain.BlogRepository for PostgreSQL.
@@ -19,13 +22,17 @@ func NewBlogRepository(db *gorm.DB) *BlogRepository {
 
 // Create creates a new blog post in the database.
 func (r *BlogRepository) Create(blog *domain.Blog) error {
-	return r.DB.Create(blog).Error
+	err := r.DB.Create(blog).Error
+	if pgErrorCode(err) == pgUniqueViolation {
+		return domain.ErrAlreadyExists // Slug taken
+	}
+	return err
 }
 
 // FindByID finds a blog post by its ID.
 func (r *BlogRepository) FindByID(id uint) (*domain.Blog, error) {
 	var blog domain.Blog
-	if err := r.DB.Preload("Category").First(&blog, id).Error; err != nil {
+	if err := r.DB.Preload("Category").Preload("Author").Preload("Tags").First(&blog, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -37,7 +44,7 @@ func (r *BlogRepository) FindByID(id uint) (*domain.Blog, error) {
 // FindBySlug finds a blog post by its slug.
 func (r *BlogRepository) FindBySlug(slug string) (*domain.Blog, error) {
 	var blog domain.Blog
-	if err := r.DB.Preload("Category").Where("slug = ?", slug).First(&blog).Error; err != nil {
+	if err := r.DB.Preload("Category").Preload("Author").Preload("Tags").Where("slug = ?", slug).First(&blog).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -49,7 +56,7 @@ func (r *BlogRepository) FindBySlug(slug string) (*domain.Blog, error) {
 // FindAll retrieves all blog posts, optionally filtered by published status.
 func (r *BlogRepository) FindAll(publishedOnly bool) ([]domain.Blog, error) {
 	var blogs []domain.Blog
-	query := r.DB.Preload("Category")
+	query := r.DB.Preload("Category").Preload("Author").Preload("Tags")
 	if publishedOnly {
 		query = query.Where("is_published = ?", true)
 	}
@@ -62,7 +69,7 @@ func (r *BlogRepository) FindAll(publishedOnly bool) ([]domain.Blog, error) {
 // FindByCategoryID retrieves blog posts by category ID, optionally filtered by published status.
 func (r *BlogRepository) FindByCategoryID(categoryID uint, publishedOnly bool) ([]domain.Blog, error) {
 	var blogs []domain.Blog
-	query := r.DB.Preload("Category").Where("category_id = ?", categoryID)
+	query := r.DB.Preload("Category").Preload("Author").Preload("Tags").Where("category_id = ?", categoryID)
 	if publishedOnly {
 		query = query.Where("is_published = ?", true)
 	}
@@ -72,9 +79,174 @@ func (r *BlogRepository) FindByCategoryID(categoryID uint, publishedOnly bool) (
 	return blogs, nil
 }
 
-// Update updates an existing blog post.
+// CountByCategoryID counts all blog posts, published or not, in the given category.
+func (r *BlogRepository) CountByCategoryID(categoryID uint) (int64, error) {
+	var count int64
+	if err := r.DB.Model(&domain.Blog{}).Where("category_id = ?", categoryID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// filtered builds the base query for a listing filter.
+func (r *BlogRepository) filtered(filter domain.BlogFilter) *gorm.DB {
+	query := r.DB.Model(&domain.Blog{})
+	if filter.PublishedOnly {
+		query = query.Where("is_published = ?", true)
+	}
+	if filter.CategoryID != 0 {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.TagID != 0 {
+		query = query.Where("id IN (SELECT blog_id FROM blog_tags WHERE tag_id = ?)", filter.TagID)
+	}
+	return query
+}
+
+// Count counts the blog posts matching the filter.
+func (r *BlogRepository) Count(filter domain.BlogFilter) (int64, error) {
+	var count int64
+	if err := r.filtered(filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindPage retrieves one page of blog posts by offset, newest first.
+func (r *BlogRepository) FindPage(filter domain.BlogFilter, offset, limit int) ([]domain.Blog, error) {
+	var blogs []domain.Blog
+	query := r.filtered(filter).Preload("Category").Preload("Author").Preload("Tags").
+		Order("time_created DESC, id DESC").Offset(offset).Limit(limit)
+	if err := query.Find(&blogs).Error; err != nil {
+		return nil, err
+	}
+	return blogs, nil
+}
+
+// FindPageByCursor retrieves up to limit blog posts next to the cursor using a keyset
+// on (time_created, id). Posts are always returned newest first.
+func (r *BlogRepository) FindPageByCursor(filter domain.BlogFilter, cursor domain.Cursor, limit int) ([]domain.Blog, error) {
+	var blogs []domain.Blog
+	query := r.filtered(filter).Preload("Category").Preload("Author").Preload("Tags").Limit(limit)
+	if cursor.Before {
+		query = query.Where("(time_created, id) > (?, ?)", cursor.TimeCreated, cursor.ID).
+			Order("time_created ASC, id ASC")
+	} else {
+		query = query.Where("(time_created, id) < (?, ?)", cursor.TimeCreated, cursor.ID).
+			Order("time_created DESC, id DESC")
+	}
+	if err := query.Find(&blogs).Error; err != nil {
+		return nil, err
+	}
+	if cursor.Before {
+		// Scanned oldest first to stay next to the cursor; flip back to newest first
+		for i, j := 0, len(blogs)-1; i < j; i, j = i+1, j-1 {
+			blogs[i], blogs[j] = blogs[j], blogs[i]
+		}
+	}
+	return blogs, nil
+}
+
+// searchHeadlineOptions configures the ts_headline snippets returned by Search.
+var searchHeadlineOptions = fmt.Sprintf(
+	`StartSel="%s", StopSel="%s", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`,
+	domain.SearchHighlightStart, domain.SearchHighlightStop,
+)
+
+// searchHit is one row of the ranked search query, before the posts are loaded.
+type searchHit struct {
+	ID      uint
+	Rank    float64
+	Snippet string
+}
+
+// Search runs a full-text query over published posts using the search_vector column.
+// The query accepts web search syntax ("quoted phrases", -excluded, OR). Results are
+// ranked by ts_rank_cd and come with a ts_headline snippet of the content.
+func (r *BlogRepository) Search(query string, page domain.PageRequest) ([]domain.SearchResult, int64, error) {
+	const match = "is_published = TRUE AND search_vector @@ websearch_to_tsquery('simple', ?)"
+
+	var total int64
+	if err := r.DB.Model(&domain.Blog{}).Where(match, query).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []domain.SearchResult{}, 0, nil
+	}
+
+	var hits []searchHit
+	err := r.DB.Raw(`
+		SELECT id,
+		       ts_rank_cd(search_vector, websearch_to_tsquery('simple', @query)) AS rank,
+		       ts_headline('simple', coalesce(content, ''), websearch_to_tsquery('simple', @query), @options) AS snippet
+		FROM blogs
+		WHERE is_published = TRUE AND search_vector @@ websearch_to_tsquery('simple', @query)
+		ORDER BY rank DESC, time_created DESC, id DESC
+		LIMIT @limit OFFSET @offset`,
+		sql.Named("query", query),
+		sql.Named("options", searchHeadlineOptions),
+		sql.Named("limit", page.PerPage),
+		sql.Named("offset", (page.Page-1)*page.PerPage),
+	).Scan(&hits).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	var blogs []domain.Blog
+	if err := r.DB.Preload("Category").Preload("Author").Preload("Tags").Find(&blogs, ids).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]domain.Blog, len(blogs))
+	for _, blog := range blogs {
+		byID[blog.ID] = blog
+	}
+
+	// Keep the ranking order of the hits
+	results := make([]domain.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		blog, ok := byID[hit.ID]
+		if !ok {
+			continue // Deleted between the two queries
+		}
+		results = append(results, domain.SearchResult{Post: blog, Rank: hit.Rank, Snippet: hit.Snippet})
+	}
+	return results, total, nil
+}
+
+// FindSitemapPage retrieves published posts with only the fields a sitemap needs,
+// in a stable order so that sitemap files split by offset do not overlap.
+func (r *BlogRepository) FindSitemapPage(offset, limit int) ([]domain.Blog, error) {
+	var blogs []domain.Blog
+	query := r.filtered(domain.BlogFilter{PublishedOnly: true}).
+		Select("id", "slug", "time_update").Order("id").Offset(offset).Limit(limit)
+	if err := query.Find(&blogs).Error; err != nil {
+		return nil, err
+	}
+	return blogs, nil
+}
+
+// Update updates an existing blog post and replaces its tags with blog.Tags.
+// Other associations are omitted so a stale preloaded Category cannot overwrite CategoryID.
 func (r *BlogRepository) Update(blog *domain.Blog) error {
-	return r.DB.Save(blog).Error
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(blog).Error; err != nil {
+			return err
+		}
+		if len(blog.Tags) == 0 {
+			return tx.Model(blog).Association("Tags").Clear()
+		}
+		return tx.Model(blog).Association("Tags").Replace(blog.Tags)
+	})
+}
+
+// UpdateContentHTML stores freshly rendered HTML without touching any other column.
+func (r *BlogRepository) UpdateContentHTML(id uint, contentHTML string, version int) error {
+	return r.DB.Model(&domain.Blog{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"content_html": contentHTML, "content_html_version": version}).Error
 }
 
 // Delete deletes a blog post by its ID.
@@ -19,7 +19,11 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
 
 // Create creates a new category in the database.
 func (r *CategoryRepository) Create(category *domain.Category) error {
-	return r.DB.Create(category).Error
+	err := r.DB.Create(category).Error
+	if pgErrorCode(err) == pgUniqueViolation {
+		return domain.ErrAlreadyExists // Name or slug taken
+	}
+	return err
 }
 
 // FindByID finds a category by its ID.
@@ -49,7 +53,7 @@ func (r *CategoryRepository) FindBySlug(slug string) (*domain.Category, error) {
 // FindAll retrieves all categories.
 func (r *CategoryRepository) FindAll() ([]domain.Category, error) {
 	var categories []domain.Category
-	if err := r.DB.Find(&categories).Error; err != nil {
+	if err := r.DB.Order("name ASC").Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	return categories, nil
@@ -57,10 +61,18 @@ func (r *CategoryRepository) FindAll() ([]domain.Category, error) {
 
 // Update updates an existing category.
 func (r *CategoryRepository) Update(category *domain.Category) error {
-	return r.DB.Save(category).Error
+	err := r.DB.Save(category).Error
+	if pgErrorCode(err) == pgUniqueViolation {
+		return domain.ErrAlreadyExists // Name or slug taken
+	}
+	return err
 }
 
 // Delete deletes a category by its ID.
 func (r *CategoryRepository) Delete(id uint) error {
-	return r.DB.Delete(&domain.Category{}, id).Error
+	err := r.DB.Delete(&domain.Category{}, id).Error
+	if pgErrorCode(err) == pgForeignKeyViolation {
+		return domain.ErrCategoryInUse // blogs.category_id is ON DELETE RESTRICT
+	}
+	return err
 }
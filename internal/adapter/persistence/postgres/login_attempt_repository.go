@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"programming_blog_go/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptRepository implements domain.LoginAttemptRepository for PostgreSQL.
+type LoginAttemptRepository struct {
+	DB *gorm.DB
+}
+
+// NewLoginAttemptRepository creates a new PostgreSQL failed login repository.
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{DB: db}
+}
+
+// ReserveAttempt counts an attempt in a transaction that holds the key's row
+// locked, so concurrent attempts see each other's count and lock. Rows of other
+// keys that no longer count are deleted first, so the table does not grow with
+// every username ever tried.
+func (r *LoginAttemptRepository) ReserveAttempt(key string, at time.Time, resetAfter time.Duration, lockout func(count int) time.Duration) (bool, bool, error) {
+	err := r.DB.
+		Where("last_failed_at < ? AND (locked_until IS NULL OR locked_until <= ?)", at.Add(-resetAfter), at).
+		Delete(&domain.LoginFailures{}).Error
+	if err != nil {
+		return false, false, err
+	}
+
+	var allowed, locked bool
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO login_failures (throttle_key, failures, last_failed_at)
+			VALUES (?, 0, ?)
+			ON CONFLICT (throttle_key) DO NOTHING`,
+			key, at,
+		).Error
+		if err != nil {
+			return err
+		}
+		var failures domain.LoginFailures
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("throttle_key = ?", key).First(&failures).Error; err != nil {
+			return err
+		}
+		if failures.LockedUntil != nil && at.Before(*failures.LockedUntil) {
+			return nil
+		}
+
+		allowed = true
+		if failures.LastFailedAt.Before(at.Add(-resetAfter)) {
+			failures.Failures = 0
+		}
+		failures.Failures++
+		failures.LastFailedAt = at
+		if d := lockout(failures.Failures); d > 0 {
+			until := at.Add(d)
+			failures.LockedUntil = &until
+			locked = true
+		}
+		return tx.Save(&failures).Error
+	})
+	if err != nil {
+		return false, false, err
+	}
+	return allowed, locked, nil
+}
+
+// ReleaseAttempt takes back an attempt.
+func (r *LoginAttemptRepository) ReleaseAttempt(key string, unlock bool) error {
+	updates := map[string]interface{}{"failures": gorm.Expr("GREATEST(failures - 1, 0)")}
+	if unlock {
+		updates["locked_until"] = nil
+	}
+	return r.DB.Model(&domain.LoginFailures{}).Where("throttle_key = ?", key).Updates(updates).Error
+}
+
+// Reset forgets the key's failures.
+func (r *LoginAttemptRepository) Reset(key string) error {
+	return r.DB.Where("throttle_key = ?", key).Delete(&domain.LoginFailures{}).Error
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_blogs_author_id;
+
+ALTER TABLE blogs DROP COLUMN IF EXISTS author_id;
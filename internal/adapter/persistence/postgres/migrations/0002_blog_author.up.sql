@@ -0,0 +1,5 @@
+-- Link blog posts to the user who wrote them
+ALTER TABLE blogs
+    ADD COLUMN IF NOT EXISTS author_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_blogs_author_id ON blogs(author_id);
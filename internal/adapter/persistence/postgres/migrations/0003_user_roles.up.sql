@@ -0,0 +1,9 @@
+-- Add roles to users. New accounts start as readers.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'reader'
+        CHECK (role IN ('reader', 'author', 'editor', 'admin'));
+
+-- Every existing user could create posts before roles existed, so keep them as authors.
+-- Promote the first administrator manually, e.g.:
+--   UPDATE users SET role = 'admin' WHERE username = '...';
+UPDATE users SET role = 'author';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_blogs_category_time_created_id;
+DROP INDEX IF EXISTS idx_blogs_time_created_id;
@@ -0,0 +1,3 @@
+-- Support keyset pagination on (time_created, id) for the home page and category listings
+CREATE INDEX IF NOT EXISTS idx_blogs_time_created_id ON blogs (time_created DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_blogs_category_time_created_id ON blogs (category_id, time_created DESC, id DESC);
@@ -0,0 +1,6 @@
+-- Posts can be written in Markdown; the sanitized HTML is cached next to the source.
+-- Existing posts stay plain text and get their HTML rendered on first view.
+ALTER TABLE blogs
+    ADD COLUMN IF NOT EXISTS content_format VARCHAR(20) NOT NULL DEFAULT 'plain'
+        CHECK (content_format IN ('plain', 'markdown')),
+    ADD COLUMN IF NOT EXISTS content_html TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Full-text search over post titles (weight A) and content (weight B).
+-- The 'simple' configuration does no stemming, so Russian and English posts are indexed alike.
+ALTER TABLE blogs
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce(content, '')), 'B')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_blogs_search_vector ON blogs USING GIN (search_vector);
@@ -0,0 +1,6 @@
+-- Users confirm their email address through a signed link sent after registration.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- Accounts from before verification existed keep working as they did.
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
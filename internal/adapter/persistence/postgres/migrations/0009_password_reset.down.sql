@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS session_version;
+
+DROP TABLE IF EXISTS password_reset_tokens;
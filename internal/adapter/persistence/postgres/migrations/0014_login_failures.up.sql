@@ -0,0 +1,8 @@
+-- Recent failed logins per account (keyed by username, known or not) and per
+-- client address, for exponential backoff and lockout.
+CREATE TABLE IF NOT EXISTS login_failures (
+    throttle_key VARCHAR(300) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,4 @@
+-- Cached HTML remembers which renderer version produced it, so posts are rendered
+-- again after the renderer changes. Everything cached so far predates versioning.
+ALTER TABLE blogs
+    ADD COLUMN IF NOT EXISTS content_html_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- When the last verification link was emailed, so resending is rate limited.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS verification_sent_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+-- Rows whose failures no longer count are deleted by last_failed_at.
+CREATE INDEX IF NOT EXISTS idx_login_failures_last_failed_at ON login_failures (last_failed_at);
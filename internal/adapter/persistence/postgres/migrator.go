@@ -0,0 +1,274 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID identifies the advisory lock held while migrating, so two deploys
+// starting at the same time apply each migration only once.
+const migrationLockID int64 = 7_412_305_118
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change with its SQL for both directions.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string // Empty if the migration cannot be reverted
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time // Nil while pending
+}
+
+// Migrator applies the embedded SQL migrations and records them in schema_migrations.
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration // Sorted by version
+}
+
+// NewMigrator creates a migrator for the migrations built into the binary.
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: sqlDB, Migrations: migrations}, nil
+}
+
+// loadMigrations reads NNNN_name.up.sql / NNNN_name.down.sql pairs from dir.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_name.up.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, done map[int64]time.Time) error {
+		for _, migration := range m.Migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+					migration.Version, migration.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts up to steps of the most recently applied migrations, newest first,
+// and returns the ones it reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, done map[int64]time.Time) error {
+		for i := len(m.Migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.Migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Baseline records every migration up to and including version as applied without
+// running it. It is meant for databases whose schema was created by hand before
+// the runner existed.
+func (m *Migrator) Baseline(ctx context.Context, version int64) ([]Migration, error) {
+	var recorded []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, done map[int64]time.Time) error {
+		for _, migration := range m.Migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			_, err := conn.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+				migration.Version, migration.Name)
+			if err != nil {
+				return err
+			}
+			recorded = append(recorded, migration)
+		}
+		return nil
+	})
+	return recorded, err
+}
+
+// Status lists every known migration and when it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.locked(ctx, func(conn *sql.Conn, done map[int64]time.Time) error {
+		for _, migration := range m.Migrations {
+			status := MigrationStatus{Migration: migration}
+			if appliedAt, ok := done[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// locked runs fn on a dedicated connection holding the migration advisory lock,
+// passing the applied versions read after the lock was taken.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, done map[int64]time.Time) error) (err error) {
+	// Session-level advisory locks belong to a connection, so take one out of the pool
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx was cancelled
+		_, unlockErr := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+		if err == nil && unlockErr != nil {
+			err = fmt.Errorf("releasing migration lock: %w", unlockErr)
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`)
+	if err != nil {
+		return err
+	}
+
+	done, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, done)
+}
+
+// appliedMigrations returns the applied versions with the time each was applied.
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = appliedAt
+	}
+	return done, rows.Err()
+}
+
+// inTx runs fn in a transaction on conn, so a failing migration leaves no trace.
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return fmt.Errorf("%w (rollback: %v)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0002_add_role.up.sql":   {Data: []byte("ALTER TABLE users ADD role TEXT;")},
+		"migrations/0002_add_role.down.sql": {Data: []byte("ALTER TABLE users DROP role;")},
+		"migrations/0001_initial.up.sql":    {Data: []byte("CREATE TABLE users (id INT);")},
+	}
+
+	migrations, err := loadMigrations(fsys, "migrations")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "initial", migrations[0].Name)
+	assert.Empty(t, migrations[0].Down)
+	assert.Equal(t, int64(2), migrations[1].Version)
+	assert.Equal(t, "ALTER TABLE users DROP role;", migrations[1].Down)
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"bad name":     {"migrations/initial.sql": {}},
+		"missing up":   {"migrations/0001_initial.down.sql": {}},
+		"name clashes": {"migrations/0001_a.up.sql": {}, "migrations/0001_b.down.sql": {}},
+	}
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadMigrations(fsys, "migrations")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	require.NoError(t, err)
+	for i, m := range migrations {
+		// Versions are consecutive and every migration can be reverted
+		assert.Equal(t, int64(i+1), m.Version)
+		assert.NotEmpty(t, m.Down, "migration %d_%s has no down file", m.Version, m.Name)
+	}
+}
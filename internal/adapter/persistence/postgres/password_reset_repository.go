@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"errors"
+	"programming_blog_go/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetRepository implements domain.PasswordResetRepository for PostgreSQL.
+type PasswordResetRepository struct {
+	DB *gorm.DB
+}
+
+// NewPasswordResetRepository creates a new PostgreSQL password reset token repository.
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{DB: db}
+}
+
+// Create stores a new password reset token.
+func (r *PasswordResetRepository) Create(token *domain.PasswordResetToken) error {
+	return r.DB.Create(token).Error
+}
+
+// FindByHash finds a token by the hash of its value.
+func (r *PasswordResetRepository) FindByHash(tokenHash string) (*domain.PasswordResetToken, error) {
+	var token domain.PasswordResetToken
+	err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Redeem deletes the token and applies the reset in one transaction. Deleting the
+// token first holds its row, so of two requests with the same link only the first
+// finds it.
+func (r *PasswordResetRepository) Redeem(tokenID uint, user *domain.User, now time.Time) (bool, error) {
+	var redeemed bool
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ? AND expires_at > ?", tokenID, user.ID, now).
+			Delete(&domain.PasswordResetToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		err := tx.Model(user).
+			Select("password", "session_version", "email_verified_at", "updated_at").
+			Updates(user).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&domain.APIToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&domain.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		redeemed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return redeemed, nil
+}
+
+// DeleteByUserID removes every outstanding token of the user.
+func (r *PasswordResetRepository) DeleteByUserID(userID uint) error {
+	return r.DB.Where("user_id = ?", userID).Delete(&domain.PasswordResetToken{}).Error
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"programming_blog_go/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeRepository implements domain.RecoveryCodeRepository for PostgreSQL.
+type RecoveryCodeRepository struct {
+	DB *gorm.DB
+}
+
+// NewRecoveryCodeRepository creates a new PostgreSQL recovery code repository.
+func NewRecoveryCodeRepository(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{DB: db}
+}
+
+// ReplaceAll swaps the user's codes for new ones in one transaction, so the old
+// codes stop working exactly when the new ones start.
+func (r *RecoveryCodeRepository) ReplaceAll(userID uint, codeHashes []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codeHashes) == 0 {
+			return nil
+		}
+		codes := make([]domain.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = domain.RecoveryCode{UserID: userID, CodeHash: hash, CreatedAt: time.Now()}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Consume marks the code used. The condition is checked in the UPDATE itself, so
+// only one concurrent request can use a code.
+func (r *RecoveryCodeRepository) Consume(userID uint, codeHash string, at time.Time) (bool, error) {
+	result := r.DB.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteByUserID removes all of the user's recovery codes.
+func (r *RecoveryCodeRepository) DeleteByUserID(userID uint) error {
+	return r.DB.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"errors"
+	"programming_blog_go/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository implements domain.RefreshTokenRepository for PostgreSQL.
+type RefreshTokenRepository struct {
+	DB *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new PostgreSQL refresh token repository.
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{DB: db}
+}
+
+// Create stores a new refresh token.
+func (r *RefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+// FindByHash finds a refresh token by the hash of its value.
+func (r *RefreshTokenRepository) FindByHash(tokenHash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	if err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks the token as rotated unless it already was or has been revoked.
+// The condition is checked in the UPDATE itself, so only one concurrent refresh wins.
+func (r *RefreshTokenRepository) MarkUsed(id uint, at time.Time) (bool, error) {
+	result := r.DB.Model(&domain.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily revokes every token rotated from the same login.
+func (r *RefreshTokenRepository) RevokeFamily(familyID string, at time.Time) error {
+	return r.DB.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"errors"
+	"programming_blog_go/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// TagRepository implements domain.TagRepository for PostgreSQL.
+type TagRepository struct {
+	DB *gorm.DB
+}
+
+// NewTagRepository creates a new PostgreSQL tag repository.
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{DB: db}
+}
+
+// Create creates a new tag in the database.
+func (r *TagRepository) Create(tag *domain.Tag) error {
+	err := r.DB.Create(tag).Error
+	if pgErrorCode(err) == pgUniqueViolation {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+// FindByID finds a tag by its ID.
+func (r *TagRepository) FindByID(id uint) (*domain.Tag, error) {
+	var tag domain.Tag
+	if err := r.DB.First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FindBySlug finds a tag by its slug.
+func (r *TagRepository) FindBySlug(slug string) (*domain.Tag, error) {
+	var tag domain.Tag
+	if err := r.DB.Where("slug = ?", slug).First(&tag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FindAll retrieves all tags.
+func (r *TagRepository) FindAll() ([]domain.Tag, error) {
+	var tags []domain.Tag
+	if err := r.DB.Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// FindPublishedCounts retrieves every tag used by at least one published post,
+// with the number of such posts.
+func (r *TagRepository) FindPublishedCounts() ([]domain.TagCount, error) {
+	var counts []domain.TagCount
+	err := r.DB.Table("tags").
+		Select("tags.*, COUNT(blogs.id) AS post_count").
+		Joins("JOIN blog_tags ON blog_tags.tag_id = tags.id").
+		Joins("JOIN blogs ON blogs.id = blog_tags.blog_id AND blogs.is_published = ?", true).
+		Group("tags.id").
+		Order("tags.name ASC").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// Delete deletes a tag by its ID. Links to posts are removed by the foreign key cascade.
+func (r *TagRepository) Delete(id uint) error {
+	return r.DB.Delete(&domain.Tag{}, id).Error
+}
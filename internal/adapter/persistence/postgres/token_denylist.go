@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenDenylist implements domain.TokenDenylist for PostgreSQL.
+type TokenDenylist struct {
+	DB *gorm.DB
+}
+
+// NewTokenDenylist creates a new PostgreSQL access token denylist.
+func NewTokenDenylist(db *gorm.DB) *TokenDenylist {
+	return &TokenDenylist{DB: db}
+}
+
+// revokedAccessToken is a row of revoked_access_tokens.
+type revokedAccessToken struct {
+	JTI       string `gorm:"column:jti;primaryKey"`
+	ExpiresAt time.Time
+}
+
+// Add revokes the access token until its expiry, and forgets tokens that have
+// expired on their own in the meantime.
+func (d *TokenDenylist) Add(tokenID string, expiresAt time.Time) error {
+	if err := d.DB.Where("expires_at < ?", time.Now()).Delete(&revokedAccessToken{}).Error; err != nil {
+		return err
+	}
+	err := d.DB.Create(&revokedAccessToken{JTI: tokenID, ExpiresAt: expiresAt}).Error
+	if pgErrorCode(err) == pgUniqueViolation {
+		return nil // Already revoked
+	}
+	return err
+}
+
+// Contains reports whether the access token has been revoked.
+func (d *TokenDenylist) Contains(tokenID string) (bool, error) {
+	var count int64
+	if err := d.DB.Model(&revokedAccessToken{}).Where("jti = ?", tokenID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
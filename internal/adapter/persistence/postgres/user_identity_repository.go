@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"errors"
+	"programming_blog_go/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository implements domain.UserIdentityRepository for PostgreSQL.
+type UserIdentityRepository struct {
+	DB *gorm.DB
+}
+
+// NewUserIdentityRepository creates a new PostgreSQL linked identity repository.
+func NewUserIdentityRepository(db *gorm.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{DB: db}
+}
+
+// FindBySubject finds the identity a provider knows by the given subject.
+func (r *UserIdentityRepository) FindBySubject(provider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	if err := r.DB.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// Create links a new identity to a user.
+func (r *UserIdentityRepository) Create(identity *domain.UserIdentity) error {
+	return r.DB.Create(identity).Error
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"errors"
 	"programming_blog_go/internal/domain"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -67,3 +68,28 @@ func (r *UserRepository) Update(user *domain.User) error {
 func (r *UserRepository) Delete(id uint) error {
 	return r.DB.Delete(&domain.User{}, id).Error
 }
+
+// AdvanceTOTPStep stores the step only if it is later than the last one used.
+// The condition is checked in the UPDATE itself, so a code cannot be replayed
+// even by concurrent requests.
+func (r *UserRepository) AdvanceTOTPStep(userID uint, step int64) (bool, error) {
+	result := r.DB.Model(&domain.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ClaimVerificationEmail stores the send time only if the previous link is older
+// than the cooldown, checked in the UPDATE itself like AdvanceTOTPStep.
+func (r *UserRepository) ClaimVerificationEmail(userID uint, at time.Time, cooldown time.Duration) (bool, error) {
+	result := r.DB.Model(&domain.User{}).
+		Where("id = ? AND (verification_sent_at IS NULL OR verification_sent_at <= ?)", userID, at.Add(-cooldown)).
+		Update("verification_sent_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
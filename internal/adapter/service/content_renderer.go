@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"programming_blog_go/internal/domain"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Footnote markup goldmark produces. Only these classes and roles are allowed on
+// links and divs, so a post cannot borrow the site's own classes to fake its UI.
+var (
+	footnoteLinkClass = regexp.MustCompile(`^footnote-(ref|backref)$`)
+	footnoteLinkRole  = regexp.MustCompile(`^doc-(noteref|backlink)$`)
+	footnotesClass    = regexp.MustCompile(`^footnotes$`)
+	footnotesRole     = regexp.MustCompile(`^doc-endnotes$`)
+)
+
+// contentRendererVersion is stored with the cached HTML of each post. Bump it when
+// a change here alters the output, e.g. new extensions or sanitizer rules.
+//
+//	1: syntax highlighting and footnote-only classes
+//	2: highlighting classes carry the "hl-" prefix
+const contentRendererVersion = 2
+
+// codeLanguageClass matches the class goldmark puts on fenced code blocks tagged with a language.
+var codeLanguageClass = regexp.MustCompile(`^language-[\w+#-]+$`)
+
+// highlightClassPrefix is put in front of every class chroma emits, so the sanitizer
+// can allow exactly those and nothing that would style a post like the site's UI.
+const highlightClassPrefix = "hl-"
+
+// highlightClasses matches the class lists chroma produces, e.g. "hl-line hl-hl".
+var highlightClasses = regexp.MustCompile(`^hl-[a-z0-9]+( hl-[a-z0-9]+)*$`)
+
+// ContentRenderer implements domain.ContentRenderer using goldmark for Markdown
+// and bluemonday to sanitize whatever HTML ends up in the page.
+type ContentRenderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+}
+
+// NewContentRenderer creates a new ContentRenderer.
+func NewContentRenderer() *ContentRenderer {
+	policy := bluemonday.UGCPolicy()
+	// Language hints on fenced code blocks, e.g. class="language-go"
+	policy.AllowAttrs("class").Matching(codeLanguageClass).OnElements("code")
+	// Class-based token spans, line numbers and highlighted lines produced by chroma
+	policy.AllowAttrs("class").Matching(highlightClasses).OnElements("pre", "span", "table", "td")
+	// Footnote references and back-links produced by goldmark
+	policy.AllowAttrs("id").Matching(bluemonday.Paragraph).OnElements("sup", "li")
+	policy.AllowAttrs("class").Matching(footnoteLinkClass).OnElements("a")
+	policy.AllowAttrs("role").Matching(footnoteLinkRole).OnElements("a")
+	policy.AllowAttrs("class").Matching(footnotesClass).OnElements("div")
+	policy.AllowAttrs("role").Matching(footnotesRole).OnElements("div")
+
+	return &ContentRenderer{
+		markdown: goldmark.New(
+			goldmark.WithExtensions(
+				extension.GFM,
+				extension.Footnote,
+				// Fences tagged with a language are highlighted into class-based spans
+				// styled by web/static/css/syntax.css (generated from the chroma "github"
+				// style). Fence attributes control line numbers and highlighted lines,
+				// e.g. ```go {linenos=false} or ```go {hl_lines=[2,"4-6"]}.
+				highlighting.NewHighlighting(
+					highlighting.WithFormatOptions(
+						chromahtml.WithClasses(true),
+						chromahtml.ClassPrefix(highlightClassPrefix),
+						chromahtml.WithLineNumbers(true),
+					),
+				),
+			),
+		),
+		policy: policy,
+	}
+}
+
+// Version returns the version of the renderer's output.
+func (r *ContentRenderer) Version() int {
+	return contentRendererVersion
+}
+
+// Render converts the content into sanitized HTML according to its format.
+func (r *ContentRenderer) Render(content, format string) (string, error) {
+	switch format {
+	case domain.ContentFormatMarkdown:
+		var buf bytes.Buffer
+		if err := r.markdown.Convert([]byte(content), &buf); err != nil {
+			return "", fmt.Errorf("failed to render markdown: %w", err)
+		}
+		return r.policy.Sanitize(buf.String()), nil
+	case domain.ContentFormatPlain, "":
+		return renderPlainText(content), nil
+	default:
+		return "", domain.ErrInvalidInput
+	}
+}
+
+// renderPlainText escapes the text and keeps its paragraphs and line breaks.
+func renderPlainText(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	var b strings.Builder
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
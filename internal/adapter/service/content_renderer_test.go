@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+
+	"programming_blog_go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentRenderer_RenderMarkdown(t *testing.T) {
+	renderer := NewContentRenderer()
+
+	content := "# Title\n\n" +
+		"| a | b |\n|---|---|\n| 1 | 2 |\n\n" +
+		"```go\nfmt.Println(\"hi\")\n```\n\n" +
+		"Note[^1]\n\n[^1]: Footnote text\n"
+
+	out, err := renderer.Render(content, domain.ContentFormatMarkdown)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "<h1>Title</h1>")
+	assert.Contains(t, out, "<table>")
+	assert.Contains(t, out, `<span class="hl-nx">fmt</span>`)
+	assert.Contains(t, out, `<li id="fn:1">`)
+	assert.Contains(t, out, `class="footnote-ref" role="doc-noteref"`)
+	assert.Contains(t, out, `class="footnote-backref" role="doc-backlink"`)
+	assert.Contains(t, out, `<div class="footnotes" role="doc-endnotes">`)
+}
+
+func TestContentRenderer_AllowsOnlyFootnoteClasses(t *testing.T) {
+	renderer := NewContentRenderer()
+
+	out := renderer.policy.Sanitize(`<div class="alert alert-danger" role="alertdialog">x</div>` +
+		`<a href="/login" class="btn btn-primary" role="button">Log in</a>` +
+		`<a href="#fn:1" class="footnotes" role="doc-endnotes">1</a>`)
+	assert.NotContains(t, out, "class=")
+	assert.NotContains(t, out, "role=")
+}
+
+func TestContentRenderer_AllowsOnlyHighlightClasses(t *testing.T) {
+	renderer := NewContentRenderer()
+
+	out := renderer.policy.Sanitize(`<pre class="container">x</pre>` +
+		`<span class="tag-weight-5">y</span>` +
+		`<span class="hl-kd error">z</span>` +
+		`<table class="table"><tr><td class="error">1</td></tr></table>`)
+	assert.NotContains(t, out, "class=")
+
+	out = renderer.policy.Sanitize(`<span class="hl-line hl-hl">ok</span>`)
+	assert.Contains(t, out, `class="hl-line hl-hl"`)
+}
+
+func TestContentRenderer_SanitizesMarkdown(t *testing.T) {
+	renderer := NewContentRenderer()
+
+	content := "<script>alert(1)</script>\n\n[link](javascript:alert(1))\n\n<img src=x onerror=alert(1)>"
+
+	out, err := renderer.Render(content, domain.ContentFormatMarkdown)
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "<script")
+	assert.NotContains(t, out, "javascript:")
+	assert.NotContains(t, out, "onerror")
+}
+
+func TestContentRenderer_RenderPlainText(t *testing.T) {
+	renderer := NewContentRenderer()
+
+	out, err := renderer.Render("Line one\nline <two>\n\nSecond paragraph", domain.ContentFormatPlain)
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>Line one<br>\nline &lt;two&gt;</p>\n<p>Second paragraph</p>\n", out)
+
+	_, err = renderer.Render("text", "rst")
+	assert.Equal(t, domain.ErrInvalidInput, err)
+}
+
+func TestContentRenderer_HighlightsCode(t *testing.T) {
+	renderer := NewContentRenderer()
+
+	content := "```go {hl_lines=[2]}\npackage main\nfunc main() {}\n```\n"
+
+	out, err := renderer.Render(content, domain.ContentFormatMarkdown)
+	assert.NoError(t, err)
+	assert.Contains(t, out, `<pre class="hl-chroma">`)
+	assert.Contains(t, out, `<span class="hl-kd">func</span>`)
+	assert.Contains(t, out, `<span class="hl-ln">1</span>`)
+	assert.Contains(t, out, `<span class="hl-line hl-hl"><span class="hl-ln">2</span>`)
+	assert.NotContains(t, out, "style=") // Colors come from the stylesheet, not inline styles
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"programming_blog_go/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms a JWTKey can use.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Keys weaker than this are refused.
+const (
+	minHMACKeyBytes = 32
+	minRSAKeyBits   = 2048
+)
+
+// JWTKey is one access token signing key, identified in tokens by the "kid" header.
+// A key loaded from a public key file can only verify tokens: that is how a retired
+// asymmetric key is kept around until the last token it signed has expired.
+type JWTKey struct {
+	ID        string
+	Algorithm string
+	signKey   interface{} // nil for verification-only keys
+	verifyKey interface{}
+}
+
+// NewHMACKey creates an HS256 key from a shared secret.
+func NewHMACKey(id string, secret []byte) *JWTKey {
+	return &JWTKey{ID: id, Algorithm: AlgorithmHS256, signKey: secret, verifyKey: secret}
+}
+
+// CheckHMACSecret refuses secrets too short to sign tokens with HS256.
+func CheckHMACSecret(secret []byte) error {
+	if len(secret) < minHMACKeyBytes {
+		return fmt.Errorf("HMAC secret must be at least %d bytes", minHMACKeyBytes)
+	}
+	return nil
+}
+
+// CanSign reports whether the key holds the private part needed to issue tokens.
+func (k *JWTKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+// LoadJWTKeys loads the keys listed as "kid:path" entries, e.g. from JWT_KEYS.
+func LoadJWTKeys(entries []string) ([]*JWTKey, error) {
+	keys := make([]*JWTKey, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		id, path, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || path == "" {
+			return nil, fmt.Errorf("invalid JWT key entry %q, expected kid:path", entry)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate JWT key id %q", id)
+		}
+		seen[id] = true
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading JWT key %q: %w", id, err)
+		}
+		key, err := ParseJWTKey(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", id, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ParseJWTKey reads a key file. PEM private keys (PKCS#8 or PKCS#1) give RS256 or
+// EdDSA signing keys, PEM public keys give verification-only keys, and anything
+// else is taken as an HS256 secret.
+func ParseJWTKey(id string, data []byte) (*JWTKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		secret := bytes.TrimSpace(data)
+		if err := CheckHMACSecret(secret); err != nil {
+			return nil, err
+		}
+		return NewHMACKey(id, secret), nil
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		return &JWTKey{ID: id, Algorithm: AlgorithmRS256, signKey: key, verifyKey: &key.PublicKey}, nil
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		return &JWTKey{ID: id, Algorithm: AlgorithmRS256, verifyKey: key}, nil
+	case ed25519.PrivateKey:
+		return &JWTKey{ID: id, Algorithm: AlgorithmEdDSA, signKey: key, verifyKey: key.Public()}, nil
+	case ed25519.PublicKey:
+		return &JWTKey{ID: id, Algorithm: AlgorithmEdDSA, verifyKey: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
+
+// publicJWK returns the key in JWK form, or false for symmetric keys.
+func (k *JWTKey) publicJWK() (domain.JSONWebKey, bool) {
+	jwk := domain.JSONWebKey{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+	switch key := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(key)
+	default:
+		return domain.JSONWebKey{}, false
+	}
+	return jwk, true
+}
+
+// signingMethod maps the key's algorithm to its JWT signing method.
+func (k *JWTKey) signingMethod() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"programming_blog_go/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned for access tokens that are malformed, forged or expired.
+var ErrInvalidToken = errors.New("invalid token")
+
+// accessTokenClaims is the JWT payload of an access token.
+type accessTokenClaims struct {
+	UserID         uint        `json:"user_id"`
+	Username       string      `json:"username"`
+	Role           domain.Role `json:"role"`
+	SessionVersion *int        `json:"sv"` // A pointer so a missing claim is told apart from 0
+	jwt.RegisteredClaims
+}
+
+// Validate implements jwt.ClaimsValidator. It runs after the standard checks and
+// requires every claim Issue sets, so tokens from before revocation existed are rejected.
+func (c *accessTokenClaims) Validate() error {
+	if c.ID == "" || c.UserID == 0 || c.SessionVersion == nil {
+		return errors.New("missing access token claims")
+	}
+	return nil
+}
+
+// JWTTokenService implements domain.AccessTokenService with signed JWTs. The first
+// key signs new tokens; the others only verify, so tokens signed with a previous
+// key keep working during a rotation. Tokens name their key in the "kid" header
+// and must carry the service's issuer and audience.
+type JWTTokenService struct {
+	Keys     []*JWTKey
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+	parser   *jwt.Parser
+}
+
+// NewJWTTokenService creates a new JWTTokenService. The first key must be able to sign.
+func NewJWTTokenService(keys []*JWTKey, issuer, audience string, ttl time.Duration) (*JWTTokenService, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no JWT signing key configured")
+	}
+	if !keys[0].CanSign() {
+		return nil, fmt.Errorf("JWT key %q has no private key and cannot sign", keys[0].ID)
+	}
+	return &JWTTokenService{
+		Keys:     keys,
+		Issuer:   issuer,
+		Audience: audience,
+		TTL:      ttl,
+		parser: jwt.NewParser(
+			jwt.WithIssuer(issuer),
+			jwt.WithAudience(audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+		),
+	}, nil
+}
+
+// Issue signs a new access token for the user.
+func (s *JWTTokenService) Issue(user *domain.User) (string, *domain.AccessClaims, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	claims := &domain.AccessClaims{
+		TokenID:        hex.EncodeToString(jti),
+		UserID:         user.ID,
+		Username:       user.Username,
+		Role:           user.Role,
+		SessionVersion: user.SessionVersion,
+		ExpiresAt:      now.Add(s.TTL).Truncate(time.Second), // JWT times have second precision
+	}
+
+	key := s.Keys[0]
+	token := jwt.NewWithClaims(key.signingMethod(), &accessTokenClaims{
+		UserID:         claims.UserID,
+		Username:       claims.Username,
+		Role:           claims.Role,
+		SessionVersion: &claims.SessionVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        claims.TokenID,
+			Issuer:    s.Issuer,
+			Subject:   fmt.Sprint(claims.UserID),
+			Audience:  jwt.ClaimStrings{s.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(claims.ExpiresAt),
+		},
+	})
+	token.Header["kid"] = key.ID
+	signed, err := token.SignedString(key.signKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// Parse verifies the token's signature, expiry, issuer and audience and returns its claims.
+func (s *JWTTokenService) Parse(tokenString string) (*domain.AccessClaims, error) {
+	var claims accessTokenClaims
+	_, err := s.parser.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		key := s.findKey(token.Header["kid"])
+		if key == nil {
+			return nil, fmt.Errorf("unknown key id: %v", token.Header["kid"])
+		}
+		// The key decides the algorithm, never the token, or an RSA public
+		// key could be abused as an HMAC secret
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.verifyKey, nil
+	})
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return &domain.AccessClaims{
+		TokenID:        claims.ID,
+		UserID:         claims.UserID,
+		Username:       claims.Username,
+		Role:           claims.Role,
+		SessionVersion: *claims.SessionVersion,
+		ExpiresAt:      claims.ExpiresAt.Time,
+	}, nil
+}
+
+// findKey looks up the key a token names. Tokens from before key ids existed
+// have no "kid" and are checked against the signing key.
+func (s *JWTTokenService) findKey(kid interface{}) *JWTKey {
+	if kid == nil {
+		return s.Keys[0]
+	}
+	id, ok := kid.(string)
+	if !ok {
+		return nil
+	}
+	for _, key := range s.Keys {
+		if key.ID == id {
+			return key
+		}
+	}
+	return nil
+}
+
+// PublicKeys implements domain.PublicKeyProvider with the asymmetric keys,
+// including the ones kept only for verification.
+func (s *JWTTokenService) PublicKeys() domain.JSONWebKeySet {
+	set := domain.JSONWebKeySet{Keys: []domain.JSONWebKey{}}
+	for _, key := range s.Keys {
+		if jwk, ok := key.publicJWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"programming_blog_go/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://blog.example.com"
+	testAudience = "https://blog.example.com"
+)
+
+func newHMACTokenService(t *testing.T, secret string, ttl time.Duration) *JWTTokenService {
+	s, err := NewJWTTokenService([]*JWTKey{NewHMACKey("default", []byte(secret))}, testIssuer, testAudience, ttl)
+	require.NoError(t, err)
+	return s
+}
+
+func TestJWTTokenService(t *testing.T) {
+	s := newHMACTokenService(t, "secret", time.Minute)
+	user := &domain.User{ID: 4, Username: "alice", Role: domain.RoleEditor, SessionVersion: 3}
+
+	token, issued, err := s.Issue(user)
+	require.NoError(t, err)
+	assert.NotEmpty(t, issued.TokenID)
+
+	claims, err := s.Parse(token)
+	require.NoError(t, err)
+	assert.Equal(t, issued.TokenID, claims.TokenID)
+	assert.Equal(t, uint(4), claims.UserID)
+	assert.Equal(t, "alice", claims.Username)
+	assert.Equal(t, domain.RoleEditor, claims.Role)
+	assert.Equal(t, 3, claims.SessionVersion)
+
+	// Each token gets its own ID
+	_, again, err := s.Issue(user)
+	require.NoError(t, err)
+	assert.NotEqual(t, issued.TokenID, again.TokenID)
+
+	// Wrong secret
+	_, err = newHMACTokenService(t, "other", time.Minute).Parse(token)
+	assert.Equal(t, ErrInvalidToken, err)
+
+	// Expired
+	expired, _, err := newHMACTokenService(t, "secret", -time.Minute).Issue(user)
+	require.NoError(t, err)
+	_, err = s.Parse(expired)
+	assert.Equal(t, ErrInvalidToken, err)
+
+	// Old-style token without jti and session version
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 4,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = s.Parse(legacy)
+	assert.Equal(t, ErrInvalidToken, err)
+
+	// Signed with the right key but for another issuer or audience, or without expiry
+	otherIssuer, err := NewJWTTokenService([]*JWTKey{NewHMACKey("default", []byte("secret"))}, "https://evil.example.com", testAudience, time.Minute)
+	require.NoError(t, err)
+	foreign, _, err := otherIssuer.Issue(user)
+	require.NoError(t, err)
+	_, err = s.Parse(foreign)
+	assert.Equal(t, ErrInvalidToken, err)
+
+	otherAudience, err := NewJWTTokenService([]*JWTKey{NewHMACKey("default", []byte("secret"))}, testIssuer, "https://api.example.com", time.Minute)
+	require.NoError(t, err)
+	foreign, _, err = otherAudience.Issue(user)
+	require.NoError(t, err)
+	_, err = s.Parse(foreign)
+	assert.Equal(t, ErrInvalidToken, err)
+
+	noExpiry, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"jti": "x", "user_id": 4, "sv": 3, "iss": testIssuer, "aud": testAudience,
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = s.Parse(noExpiry)
+	assert.Equal(t, ErrInvalidToken, err)
+
+	noSessionVersion, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"jti": "x", "user_id": 4, "iss": testIssuer, "aud": testAudience, "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = s.Parse(noSessionVersion)
+	assert.Equal(t, ErrInvalidToken, err)
+
+	// Unsigned
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"jti": "x", "user_id": 4, "sv": 3, "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	_, err = s.Parse(unsigned)
+	assert.Equal(t, ErrInvalidToken, err)
+}
+
+func TestJWTTokenService_Rotation(t *testing.T) {
+	user := &domain.User{ID: 4, Username: "alice", Role: domain.RoleEditor, SessionVersion: 3}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	oldKey := NewHMACKey("2026-01", []byte("an-old-secret-that-is-32-bytes!!"))
+	edSigner := &JWTKey{ID: "2026-04", Algorithm: AlgorithmEdDSA, signKey: edKey, verifyKey: edKey.Public()}
+	rsaSigner := &JWTKey{ID: "2026-07", Algorithm: AlgorithmRS256, signKey: rsaKey, verifyKey: &rsaKey.PublicKey}
+
+	before, err := NewJWTTokenService([]*JWTKey{oldKey}, testIssuer, testAudience, time.Minute)
+	require.NoError(t, err)
+	oldToken, _, err := before.Issue(user)
+	require.NoError(t, err)
+
+	// The new key signs, the old one still verifies
+	for _, signer := range []*JWTKey{edSigner, rsaSigner} {
+		during, err := NewJWTTokenService([]*JWTKey{signer, oldKey}, testIssuer, testAudience, time.Minute)
+		require.NoError(t, err)
+		newToken, _, err := during.Issue(user)
+		require.NoError(t, err)
+
+		header, _, err := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+		require.NoError(t, err)
+		assert.Equal(t, signer.ID, header.Header["kid"])
+		assert.Equal(t, signer.Algorithm, header.Header["alg"])
+
+		claims, err := during.Parse(newToken)
+		require.NoError(t, err)
+		assert.Equal(t, uint(4), claims.UserID)
+		_, err = during.Parse(oldToken)
+		assert.NoError(t, err, "old key must verify during rotation")
+
+		// Once the old key is dropped its tokens stop working
+		after, err := NewJWTTokenService([]*JWTKey{signer}, testIssuer, testAudience, time.Minute)
+		require.NoError(t, err)
+		_, err = after.Parse(oldToken)
+		assert.Equal(t, ErrInvalidToken, err)
+		_, err = before.Parse(newToken)
+		assert.Equal(t, ErrInvalidToken, err)
+	}
+
+	// An HS256 token signed with the RSA public key as secret must not verify
+	pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"jti": "x", "user_id": 4, "sv": 3, "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = rsaSigner.ID
+	forgedToken, err := forged.SignedString(pubPEM)
+	require.NoError(t, err)
+	rsaOnly, err := NewJWTTokenService([]*JWTKey{rsaSigner}, testIssuer, testAudience, time.Minute)
+	require.NoError(t, err)
+	_, err = rsaOnly.Parse(forgedToken)
+	assert.Equal(t, ErrInvalidToken, err)
+}
+
+func TestLoadJWTKeys(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	keys, err := LoadJWTKeys([]string{
+		"ed:" + write("ed.pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDER})),
+		"rsa-old:" + write("rsa.pub.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPubDER})),
+		"hs:" + write("hs.key", []byte("0123456789abcdef0123456789abcdef\n")),
+	})
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+	assert.Equal(t, AlgorithmEdDSA, keys[0].Algorithm)
+	assert.True(t, keys[0].CanSign())
+	assert.Equal(t, AlgorithmRS256, keys[1].Algorithm)
+	assert.False(t, keys[1].CanSign())
+	assert.Equal(t, AlgorithmHS256, keys[2].Algorithm)
+
+	// Only asymmetric keys are published
+	s, err := NewJWTTokenService(keys, testIssuer, testAudience, time.Minute)
+	require.NoError(t, err)
+	jwks := s.PublicKeys()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, domain.JSONWebKey{KeyType: "OKP", KeyID: "ed", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519",
+		X: base64.RawURLEncoding.EncodeToString(edPublic)}, jwks.Keys[0])
+	assert.Equal(t, "RSA", jwks.Keys[1].KeyType)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+
+	// A verification-only key cannot be the signing key
+	_, err = NewJWTTokenService(keys[1:], testIssuer, testAudience, time.Minute)
+	assert.Error(t, err)
+
+	// Malformed entries, short secrets and duplicates are refused
+	_, err = LoadJWTKeys([]string{"no-path"})
+	assert.Error(t, err)
+	_, err = LoadJWTKeys([]string{"short:" + write("short.key", []byte("secret"))})
+	assert.Error(t, err)
+	_, err = LoadJWTKeys([]string{"hs:" + filepath.Join(dir, "hs.key"), "hs:" + filepath.Join(dir, "hs.key")})
+	assert.Error(t, err)
+
+	// The same minimum applies to JWT_SECRET
+	assert.Error(t, CheckHMACSecret([]byte("supersecretjwtkey")))
+	assert.NoError(t, CheckHMACSecret([]byte("0123456789abcdef0123456789abcdef")))
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"programming_blog_go/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// Responses from the provider larger than this are refused
+	maxOIDCResponseBytes = 1 << 20
+	// The provider's keys are fetched again for an unknown "kid" at most this often
+	oidcKeyRefreshInterval = time.Minute
+	// Tolerated clock difference when checking ID token times
+	oidcClockSkew = time.Minute
+)
+
+// ID token signing algorithms accepted from a provider.
+var oidcSigningAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "ES512", "EdDSA"}
+
+// OIDCProviderConfig describes an OpenID Connect provider registered for login.
+type OIDCProviderConfig struct {
+	ID           string
+	Name         string
+	IssuerURL    string // Discovery document: IssuerURL + "/.well-known/openid-configuration"
+	ClientID     string
+	ClientSecret string // Empty for public clients, which rely on PKCE alone
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OIDCProvider implements domain.IdentityProvider for an OpenID Connect provider.
+// The discovery document is fetched on first use rather than at startup, so a
+// provider that is down does not keep the blog from starting.
+type OIDCProvider struct {
+	cfg    OIDCProviderConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	discovery   *oidcDiscovery
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+// oidcDiscovery is the part of the discovery document the login flow needs.
+type oidcDiscovery struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+// NewOIDCProvider creates a provider; client defaults to one with a 10 second timeout.
+func NewOIDCProvider(cfg OIDCProviderConfig, client *http.Client) *OIDCProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if cfg.Name == "" {
+		cfg.Name = cfg.ID
+	}
+	return &OIDCProvider{cfg: cfg, client: client}
+}
+
+// ID returns the provider's identifier.
+func (p *OIDCProvider) ID() string { return p.cfg.ID }
+
+// DisplayName returns the name shown on the login button.
+func (p *OIDCProvider) DisplayName() string { return p.cfg.Name }
+
+// AuthCodeURL returns the provider's authorization URL for an S256 PKCE login.
+func (p *OIDCProvider) AuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	discovery, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("oidc %s: authorization endpoint: %w", p.cfg.ID, err)
+	}
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String(), nil
+}
+
+// Exchange redeems the authorization code at the token endpoint and verifies the
+// returned ID token: signature, issuer, audience and expiry.
+func (p *OIDCProvider) Exchange(code, codeVerifier string) (*domain.ExternalIdentity, error) {
+	discovery, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic, the default authentication method (RFC 6749 section 2.3.1)
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(req, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc %s: token endpoint: %d %s %s", p.cfg.ID, status, tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("oidc %s: token response has no id_token", p.cfg.ID)
+	}
+	return p.verifyIDToken(tokens.IDToken, discovery)
+}
+
+// idTokenClaims are the ID token claims the login uses.
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string       `json:"nonce"`
+	AuthorizedParty   string       `json:"azp"`
+	Email             string       `json:"email"`
+	EmailVerified     flexibleBool `json:"email_verified"`
+	Name              string       `json:"name"`
+	PreferredUsername string       `json:"preferred_username"`
+}
+
+// flexibleBool accepts both true and "true": some providers send email_verified as a string.
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*b = flexibleBool(v)
+	case string:
+		*b = flexibleBool(v == "true")
+	default:
+		*b = false
+	}
+	return nil
+}
+
+func (p *OIDCProvider) verifyIDToken(idToken string, discovery *oidcDiscovery) (*domain.ExternalIdentity, error) {
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(idToken, &claims, p.keyFunc,
+		jwt.WithValidMethods(oidcSigningAlgorithms),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc %s: id token: %w", p.cfg.ID, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("oidc %s: id token has no subject", p.cfg.ID)
+	}
+	// A token issued to several audiences must name us as the party it was issued to
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("oidc %s: id token was issued to %q", p.cfg.ID, claims.AuthorizedParty)
+	}
+	return &domain.ExternalIdentity{
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     bool(claims.EmailVerified),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+		Nonce:             claims.Nonce,
+	}, nil
+}
+
+// keyFunc finds the provider key that signed the token, fetching the key set
+// again when the "kid" is new, since providers rotate their keys.
+func (p *OIDCProvider) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetched) < oidcKeyRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if err := p.fetchKeys(); err != nil {
+		return nil, err
+	}
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey returns the key with the given ID; a token without "kid" may use the
+// only key of a single-key set. Callers hold p.mu.
+func (p *OIDCProvider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// fetchKeys downloads the provider's JSON Web Key Set. Callers hold p.mu.
+func (p *OIDCProvider) fetchKeys() error {
+	if p.discovery == nil {
+		return errors.New("provider not discovered")
+	}
+	req, err := http.NewRequest(http.MethodGet, p.discovery.JWKSURI, nil)
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	status, err := p.doJSON(req, &set)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("oidc %s: jwks: status %d", p.cfg.ID, status)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, raw := range set.Keys {
+		kid, key, err := parsePublicJWK(raw)
+		if err != nil {
+			continue // Keys of unsupported types or for encryption are skipped
+		}
+		keys[kid] = key
+	}
+	p.keys = keys
+	p.keysFetched = time.Now()
+	return nil
+}
+
+// parsePublicJWK turns an RFC 7517 signing key into a crypto public key.
+func parsePublicJWK(raw json.RawMessage) (string, interface{}, error) {
+	var jwk struct {
+		domain.JSONWebKey
+		Y string `json:"y"` // EC public key
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return "", nil, err
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return "", nil, fmt.Errorf("key %q is not for signatures", jwk.KeyID)
+	}
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return "", nil, err
+		}
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if key.N.BitLen() < minRSAKeyBits {
+			return "", nil, fmt.Errorf("RSA key %q is too short", jwk.KeyID)
+		}
+		return jwk.KeyID, key, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return "", nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return "", nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return "", nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return "", nil, fmt.Errorf("EC key %q is not on its curve", jwk.KeyID)
+		}
+		return jwk.KeyID, key, nil
+	case "OKP":
+		x, err := decode(jwk.X)
+		if err != nil || jwk.Curve != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return "", nil, fmt.Errorf("unsupported OKP key %q", jwk.KeyID)
+		}
+		return jwk.KeyID, ed25519.PublicKey(x), nil
+	default:
+		return "", nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
+
+// discover fetches and caches the discovery document. Its issuer must be exactly
+// the configured one, or tokens from another issuer could be accepted.
+func (p *OIDCProvider) discover() (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(p.cfg.IssuerURL, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var discovery oidcDiscovery
+	status, err := p.doJSON(req, &discovery)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc %s: discovery: status %d", p.cfg.ID, status)
+	}
+	if discovery.Issuer != p.cfg.IssuerURL {
+		return nil, fmt.Errorf("oidc %s: discovery issuer %q does not match %q", p.cfg.ID, discovery.Issuer, p.cfg.IssuerURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc %s: discovery document is missing endpoints", p.cfg.ID)
+	}
+	if len(discovery.CodeChallengeMethodsSupported) > 0 && !contains(discovery.CodeChallengeMethodsSupported, "S256") {
+		return nil, fmt.Errorf("oidc %s: provider does not support PKCE with S256", p.cfg.ID)
+	}
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// doJSON sends the request and decodes a JSON response of any status.
+func (p *OIDCProvider) doJSON(req *http.Request, v interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("oidc %s: %w", p.cfg.ID, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOIDCResponseBytes)).Decode(v); err != nil {
+		return resp.StatusCode, fmt.Errorf("oidc %s: decoding %s: %w", p.cfg.ID, req.URL.Path, err)
+	}
+	return resp.StatusCode, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
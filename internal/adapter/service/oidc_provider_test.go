@@ -0,0 +1,271 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testClientID     = "blog"
+	testClientSecret = "s3cret&more"
+	testRedirectURL  = "https://blog.example.com/auth/oidc/company/callback"
+)
+
+// fakeIdP is a minimal OpenID Connect provider: discovery, JWKS and a token
+// endpoint that checks client authentication and PKCE before issuing ID tokens.
+type fakeIdP struct {
+	server *httptest.Server
+	issuer string // Reported in discovery and ID tokens; the server URL unless changed
+
+	mu    sync.Mutex
+	kid   string
+	key   *rsa.PrivateKey
+	codes map[string]authorization
+	// Changes the ID token claims before signing, to issue bad tokens
+	tamper func(claims jwt.MapClaims)
+}
+
+type authorization struct {
+	challenge string
+	nonce     string
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	idp := &fakeIdP{codes: map[string]authorization{}}
+	idp.rotateKey(t, "key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                           idp.issuer,
+			"authorization_endpoint":           idp.server.URL + "/authorize?tenant=1",
+			"token_endpoint":                   idp.server.URL + "/token",
+			"jwks_uri":                         idp.server.URL + "/jwks",
+			"code_challenge_methods_supported": []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": idp.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	idp.issuer = idp.server.URL
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *fakeIdP) rotateKey(t *testing.T, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.kid, idp.key = kid, key
+}
+
+// authorize stands in for the user logging in at the provider and returns the code
+// the browser would bring back.
+func (idp *fakeIdP) authorize(t *testing.T, authURL string) string {
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := u.Query()
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+	code := "code-" + query.Get("state")
+	idp.mu.Lock()
+	idp.codes[code] = authorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	idp.mu.Unlock()
+	return code
+}
+
+func (idp *fakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	fail := func(code string) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": code})
+	}
+	user, pass, _ := r.BasicAuth()
+	user, _ = url.QueryUnescape(user)
+	pass, _ = url.QueryUnescape(pass)
+	if r.Method != http.MethodPost || user != testClientID || pass != testClientSecret {
+		fail("invalid_client")
+		return
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	auth, ok := idp.codes[r.PostFormValue("code")]
+	delete(idp.codes, r.PostFormValue("code")) // Codes work once
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("redirect_uri") != testRedirectURL ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+		fail("invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            idp.issuer,
+		"sub":            "user-42",
+		"aud":            testClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          auth.nonce,
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"name":           "Alice",
+	}
+	if idp.tamper != nil {
+		idp.tamper(claims)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = idp.kid
+	signed, err := token.SignedString(idp.key)
+	if err != nil {
+		fail("server_error")
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": signed})
+}
+
+func newTestOIDCProvider(idp *fakeIdP) *OIDCProvider {
+	return NewOIDCProvider(OIDCProviderConfig{
+		ID:           "company",
+		Name:         "Company SSO",
+		IssuerURL:    idp.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	}, idp.server.Client())
+}
+
+// login runs the authorization code flow against the fake provider.
+func login(t *testing.T, idp *fakeIdP, p *OIDCProvider, nonce string) (string, string) {
+	verifier := "verifier-" + nonce
+	sum := sha256.Sum256([]byte(verifier))
+	authURL, err := p.AuthCodeURL("state-"+nonce, nonce, base64.RawURLEncoding.EncodeToString(sum[:]))
+	require.NoError(t, err)
+	return idp.authorize(t, authURL), verifier
+}
+
+func TestOIDCProvider_LoginFlow(t *testing.T) {
+	idp := newFakeIdP(t)
+	p := newTestOIDCProvider(idp)
+
+	authURL, err := p.AuthCodeURL("state", "nonce", "challenge")
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := u.Query()
+	assert.Equal(t, "1", query.Get("tenant"), "query of the endpoint is kept")
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, testClientID, query.Get("client_id"))
+	assert.Equal(t, testRedirectURL, query.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "state", query.Get("state"))
+	assert.Equal(t, "nonce", query.Get("nonce"))
+	assert.Equal(t, "challenge", query.Get("code_challenge"))
+
+	code, verifier := login(t, idp, p, "n1")
+	identity, err := p.Exchange(code, verifier)
+	require.NoError(t, err)
+	assert.Equal(t, "user-42", identity.Subject)
+	assert.Equal(t, "alice@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "Alice", identity.Name)
+	assert.Equal(t, "n1", identity.Nonce)
+
+	// A code works once
+	_, err = p.Exchange(code, verifier)
+	assert.Error(t, err)
+
+	// Without the right PKCE verifier a stolen code is useless
+	code, _ = login(t, idp, p, "n2")
+	_, err = p.Exchange(code, "someone-elses-verifier")
+	assert.Error(t, err)
+}
+
+func TestOIDCProvider_RejectsBadIDTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(claims jwt.MapClaims)
+	}{
+		{"other audience", func(c jwt.MapClaims) { c["aud"] = "another-app" }},
+		{"other issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"no expiry", func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"no subject", func(c jwt.MapClaims) { delete(c, "sub") }},
+		{"issued to another party", func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "another-app"}
+			c["azp"] = "another-app"
+		}},
+	}
+	idp := newFakeIdP(t)
+	p := newTestOIDCProvider(idp)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.tamper = tt.tamper
+			code, verifier := login(t, idp, p, "n")
+			_, err := p.Exchange(code, verifier)
+			assert.Error(t, err)
+		})
+	}
+
+	// email_verified sent as a string still counts
+	idp.tamper = func(c jwt.MapClaims) { c["email_verified"] = "true" }
+	code, verifier := login(t, idp, p, "n")
+	identity, err := p.Exchange(code, verifier)
+	require.NoError(t, err)
+	assert.True(t, identity.EmailVerified)
+}
+
+func TestOIDCProvider_KeyRotation(t *testing.T) {
+	idp := newFakeIdP(t)
+	p := newTestOIDCProvider(idp)
+
+	code, verifier := login(t, idp, p, "n1")
+	_, err := p.Exchange(code, verifier)
+	require.NoError(t, err)
+
+	// A token signed with a new key is accepted once the key set is fetched again,
+	// which happens at most once per oidcKeyRefreshInterval
+	idp.rotateKey(t, "key-2")
+	code, verifier = login(t, idp, p, "n2")
+	_, err = p.Exchange(code, verifier)
+	assert.Error(t, err, "keys were fetched moments ago")
+
+	p.keysFetched = time.Now().Add(-oidcKeyRefreshInterval)
+	code, verifier = login(t, idp, p, "n3")
+	_, err = p.Exchange(code, verifier)
+	assert.NoError(t, err)
+}
+
+func TestOIDCProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	idp := newFakeIdP(t)
+	idp.issuer = "https://impostor.example.com"
+	p := newTestOIDCProvider(idp)
+
+	_, err := p.AuthCodeURL("state", "nonce", "challenge")
+	assert.ErrorContains(t, err, "does not match")
+}
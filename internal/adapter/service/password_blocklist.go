@@ -0,0 +1,59 @@
+package service
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// commonPasswords are the most frequent passwords from public breach corpora,
+// one per line.
+//
+//go:embed passwords/common.txt
+var commonPasswords string
+
+// PasswordBlocklist implements domain.PasswordBlocklist with an in-memory set.
+// Matching ignores case, so "Password1" is as blocked as "password1".
+type PasswordBlocklist struct {
+	passwords map[string]struct{}
+}
+
+// NewPasswordBlocklist creates a blocklist of the built-in common passwords and
+// those in the given files, e.g. a larger list of breached passwords.
+func NewPasswordBlocklist(paths ...string) (*PasswordBlocklist, error) {
+	b := &PasswordBlocklist{passwords: map[string]struct{}{}}
+	if err := b.read(strings.NewReader(commonPasswords)); err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		err = b.read(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+	return b, nil
+}
+
+// read adds one password per line; empty lines are skipped.
+func (b *PasswordBlocklist) read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if password := strings.TrimRight(scanner.Text(), "\r"); password != "" {
+			b.passwords[strings.ToLower(password)] = struct{}{}
+		}
+	}
+	return scanner.Err()
+}
+
+func (b *PasswordBlocklist) Contains(password string) bool {
+	_, ok := b.passwords[strings.ToLower(password)]
+	return ok
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnsupportedHash is returned for stored hashes no configured algorithm can read.
+var ErrUnsupportedHash = errors.New("unsupported password hash")
+
+// PasswordAlgorithm is one password hashing algorithm with its parameters.
+type PasswordAlgorithm interface {
+	Hash(password string) (string, error)
+	Verify(password, hash string) (bool, error)
+	NeedsRehash(hash string) bool
+	// Supports reports whether the hash was made by this algorithm.
+	Supports(hash string) bool
+}
+
+// PasswordHashers implements domain.PasswordHasher over several algorithms: new
+// hashes use the current one, while hashes of the others still verify and are
+// reported as needing a rehash. This lets the algorithm or its cost change
+// without resetting anyone's password.
+type PasswordHashers struct {
+	current PasswordAlgorithm
+	all     []PasswordAlgorithm
+}
+
+// NewPasswordHasher hashes with current and also verifies hashes made by others.
+func NewPasswordHasher(current PasswordAlgorithm, others ...PasswordAlgorithm) *PasswordHashers {
+	return &PasswordHashers{current: current, all: append([]PasswordAlgorithm{current}, others...)}
+}
+
+func (h *PasswordHashers) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+func (h *PasswordHashers) Verify(password, hash string) (bool, error) {
+	for _, algorithm := range h.all {
+		if algorithm.Supports(hash) {
+			return algorithm.Verify(password, hash)
+		}
+	}
+	return false, ErrUnsupportedHash
+}
+
+func (h *PasswordHashers) NeedsRehash(hash string) bool {
+	return !h.current.Supports(hash) || h.current.NeedsRehash(hash)
+}
+
+// BcryptHasher hashes passwords with bcrypt. Only the first 72 bytes of a
+// password count, and longer ones are refused.
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcryptHasher creates a BcryptHasher, checking the cost.
+func NewBcryptHasher(cost int) (BcryptHasher, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return BcryptHasher{}, fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, cost)
+	}
+	return BcryptHasher{Cost: cost}, nil
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h BcryptHasher) Verify(password, hash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < h.Cost
+}
+
+func (h BcryptHasher) Supports(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// Argon2idHasher hashes passwords with Argon2id (RFC 9106), stored in the PHC
+// string format: $argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>.
+type Argon2idHasher struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+const argon2idPrefix = "$argon2id$"
+
+// NewArgon2idHasher creates an Argon2idHasher with 16-byte salts and 32-byte
+// keys, checking the parameters.
+func NewArgon2idHasher(memoryKiB, iterations, parallelism int) (Argon2idHasher, error) {
+	switch {
+	case parallelism < 1 || parallelism > 255:
+		return Argon2idHasher{}, fmt.Errorf("argon2id parallelism must be between 1 and 255, got %d", parallelism)
+	case iterations < 1:
+		return Argon2idHasher{}, fmt.Errorf("argon2id iterations must be at least 1, got %d", iterations)
+	case memoryKiB < 8*parallelism || memoryKiB > maxArgon2idMemory:
+		return Argon2idHasher{}, fmt.Errorf("argon2id memory must be between %d and %d KiB, got %d", 8*parallelism, maxArgon2idMemory, memoryKiB)
+	}
+	return Argon2idHasher{
+		Memory:      uint32(memoryKiB),
+		Iterations:  uint32(iterations),
+		Parallelism: uint8(parallelism),
+		SaltLength:  16,
+		KeyLength:   32,
+	}, nil
+}
+
+// argon2idHash is a parsed Argon2id PHC string.
+type argon2idHash struct {
+	params Argon2idHasher
+	salt   []byte
+	key    []byte
+}
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h Argon2idHasher) Verify(password, hash string) (bool, error) {
+	parsed, err := parseArgon2idHash(hash)
+	if err != nil {
+		return false, err
+	}
+	p := parsed.params
+	key := argon2.IDKey([]byte(password), parsed.salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return subtle.ConstantTimeCompare(key, parsed.key) == 1, nil
+}
+
+func (h Argon2idHasher) NeedsRehash(hash string) bool {
+	parsed, err := parseArgon2idHash(hash)
+	if err != nil {
+		return true
+	}
+	p := parsed.params
+	return p.Memory < h.Memory || p.Iterations < h.Iterations || p.Parallelism != h.Parallelism ||
+		p.SaltLength < h.SaltLength || p.KeyLength < h.KeyLength
+}
+
+func (h Argon2idHasher) Supports(hash string) bool {
+	return strings.HasPrefix(hash, argon2idPrefix)
+}
+
+// maxArgon2idMemory bounds the memory a stored hash may ask for (4 GiB), so a
+// corrupted hash cannot exhaust the server.
+const maxArgon2idMemory = 4 << 20
+
+func parseArgon2idHash(hash string) (*argon2idHash, error) {
+	// "", "argon2id", "v=19", "m=…,t=…,p=…", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnsupportedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, ErrUnsupportedHash
+	}
+	var parsed argon2idHash
+	p := &parsed.params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return nil, ErrUnsupportedHash
+	}
+	if p.Memory == 0 || p.Memory > maxArgon2idMemory || p.Iterations == 0 || p.Parallelism == 0 {
+		return nil, ErrUnsupportedHash
+	}
+	var err error
+	if parsed.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrUnsupportedHash
+	}
+	if parsed.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(parsed.key) == 0 {
+		return nil, ErrUnsupportedHash
+	}
+	p.SaltLength, p.KeyLength = uint32(len(parsed.salt)), uint32(len(parsed.key))
+	return &parsed, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cheap parameters keep the tests fast; production uses the configured ones.
+func testArgon2idHasher(t *testing.T, iterations int) Argon2idHasher {
+	h, err := NewArgon2idHasher(64, iterations, 1)
+	require.NoError(t, err)
+	return h
+}
+
+func TestArgon2idHasher(t *testing.T) {
+	h := testArgon2idHasher(t, 2)
+
+	hash, err := h.Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=2,p=1$"), hash)
+	assert.True(t, h.Supports(hash))
+	assert.False(t, h.NeedsRehash(hash))
+
+	ok, err := h.Verify("correct horse", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = h.Verify("wrong horse", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Salted: the same password hashes differently each time
+	again, err := h.Hash("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, again)
+
+	// Hashes keep their own parameters, so raising them only asks for a rehash
+	stronger := testArgon2idHasher(t, 3)
+	assert.True(t, stronger.NeedsRehash(hash))
+	ok, err = stronger.Verify("correct horse", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	for _, bad := range []string{
+		"$argon2id$v=19$m=64,t=2,p=1$c29tZXNhbHQ",
+		"$argon2id$v=16$m=64,t=2,p=1$c29tZXNhbHQ$AAAA",
+		"$argon2id$v=19$m=0,t=2,p=1$c29tZXNhbHQ$AAAA",
+		"$argon2id$v=19$m=64,t=2,p=1$not base64!$AAAA",
+	} {
+		_, err := h.Verify("password", bad)
+		assert.ErrorIs(t, err, ErrUnsupportedHash, bad)
+	}
+}
+
+func TestNewArgon2idHasher_RejectsBadParameters(t *testing.T) {
+	_, err := NewArgon2idHasher(64, 0, 1)
+	assert.Error(t, err)
+	_, err = NewArgon2idHasher(64, 1, 0)
+	assert.Error(t, err)
+	_, err = NewArgon2idHasher(8, 1, 2)
+	assert.Error(t, err)
+	_, err = NewBcryptHasher(3)
+	assert.Error(t, err)
+}
+
+func TestPasswordHashers_UpgradesFromBcrypt(t *testing.T) {
+	legacy, err := NewBcryptHasher(bcrypt.MinCost)
+	require.NoError(t, err)
+	argon := testArgon2idHasher(t, 1)
+	h := NewPasswordHasher(argon, legacy)
+
+	// Hashes from before the switch still verify but ask to be replaced
+	old, err := legacy.Hash("hunter22")
+	require.NoError(t, err)
+	ok, err := h.Verify("hunter22", old)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, h.NeedsRehash(old))
+
+	current, err := h.Hash("hunter22")
+	require.NoError(t, err)
+	assert.True(t, argon.Supports(current))
+	assert.False(t, h.NeedsRehash(current))
+
+	// A higher bcrypt cost marks cheaper bcrypt hashes as outdated too
+	costlier := NewPasswordHasher(BcryptHasher{Cost: bcrypt.MinCost + 1})
+	assert.True(t, costlier.NeedsRehash(old))
+
+	_, err = h.Verify("hunter22", "")
+	assert.ErrorIs(t, err, ErrUnsupportedHash)
+	_, err = h.Verify("hunter22", "plaintext")
+	assert.ErrorIs(t, err, ErrUnsupportedHash)
+}
+
+func TestPasswordBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, []byte("Tr0ub4dor&3\r\n\ncorrect horse battery staple\n"), 0o600))
+
+	b, err := NewPasswordBlocklist(path)
+	require.NoError(t, err)
+	assert.True(t, b.Contains("password"), "built-in list")
+	assert.True(t, b.Contains("QWERTY123"), "case does not matter")
+	assert.True(t, b.Contains("tr0ub4dor&3"))
+	assert.True(t, b.Contains("correct horse battery staple"))
+	assert.False(t, b.Contains(""))
+	assert.False(t, b.Contains("a long and unlikely passphrase"))
+
+	_, err = NewPasswordBlocklist(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCiphertext is returned for stored secrets that were tampered with or
+// encrypted with another key.
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// AESCipher implements domain.SecretCipher with AES-256-GCM. Each value gets a
+// random nonce, stored in front of the ciphertext.
+type AESCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESCipher creates an AESCipher from a 32-byte key.
+func NewAESCipher(key []byte) (*AESCipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESCipher{aead: aead}, nil
+}
+
+// Encrypt seals the plaintext and returns it base64url-encoded.
+func (c *AESCipher) Encrypt(plaintext []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt.
+func (c *AESCipher) Decrypt(ciphertext string) ([]byte, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESCipher(t *testing.T) {
+	c, err := NewAESCipher([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	sealed, err := c.Encrypt([]byte("totp key"))
+	require.NoError(t, err)
+	again, err := c.Encrypt([]byte("totp key"))
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "nonces must differ")
+
+	opened, err := c.Decrypt(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("totp key"), opened)
+
+	// Another key or a modified value fails
+	other, err := NewAESCipher([]byte("fedcba9876543210fedcba9876543210"))
+	require.NoError(t, err)
+	_, err = other.Decrypt(sealed)
+	assert.Equal(t, ErrInvalidCiphertext, err)
+	_, err = c.Decrypt(sealed[:len(sealed)-2] + "AA")
+	assert.Equal(t, ErrInvalidCiphertext, err)
+
+	_, err = NewAESCipher([]byte("short"))
+	assert.Error(t, err)
+}
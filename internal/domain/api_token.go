@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIToken is a personal access token a user creates for scripts and CI jobs, so
+// they never need the password. It acts for the user, but only within its scopes,
+// and the database keeps only its hash: the token itself is shown once.
+type APIToken struct {
+	ID         uint        `json:"id"`
+	UserID     uint        `json:"user_id"`
+	Name       string      `json:"name"`
+	TokenHash  string      `json:"-"`
+	Prefix     string      `json:"prefix"` // First characters of the token, to tell tokens apart
+	Scopes     TokenScopes `json:"scopes" gorm:"type:text"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+	LastUsedAt *time.Time  `json:"last_used_at"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// TokenScopes are the permissions an API token is limited to. The user's role
+// still applies: a scope the role does not grant gives nothing.
+// They are stored as a space-separated list, like OAuth scopes.
+type TokenScopes []Permission
+
+// Contains reports whether the permission is one of the scopes.
+func (s TokenScopes) Contains(permission Permission) bool {
+	for _, p := range s {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Value implements driver.Valuer.
+func (s TokenScopes) Value() (driver.Value, error) {
+	names := make([]string, len(s))
+	for i, p := range s {
+		names[i] = string(p)
+	}
+	return strings.Join(names, " "), nil
+}
+
+// Scan implements sql.Scanner.
+func (s *TokenScopes) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T into TokenScopes", value)
+	}
+	scopes := TokenScopes{}
+	for _, name := range strings.Fields(text) {
+		scopes = append(scopes, Permission(name))
+	}
+	*s = scopes
+	return nil
+}
+
+// APITokenRepository defines the interface for interacting with API tokens.
+type APITokenRepository interface {
+	Create(token *APIToken) error
+	FindByHash(tokenHash string) (*APIToken, error)
+	// ListByUserID returns the user's tokens, newest first.
+	ListByUserID(userID uint) ([]APIToken, error)
+	// Delete removes the token if it belongs to the user; false means it did not exist.
+	Delete(id, userID uint) (bool, error)
+	// DeleteByUserID removes all of the user's tokens.
+	DeleteByUserID(userID uint) error
+	TouchLastUsed(id uint, at time.Time) error
+}
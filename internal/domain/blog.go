@@ -6,16 +6,35 @@ import (
 
 // Blog represents a blog post.
 type Blog struct {
-	ID          uint      `json:"id"`
-	Title       string    `json:"title"`
-	Slug        string    `json:"slug"`
-	Content     string    `json:"content"`
-	Photo       string    `json:"photo"`
-	TimeCreated time.Time `json:"time_created"`
-	TimeUpdate  time.Time `json:"time_update"`
-	IsPublished bool      `json:"is_published"`
-	CategoryID  uint      `json:"category_id"`
-	Category    *Category `json:"category,omitempty"` // Omitempty for optional eager loading
+	ID            uint   `json:"id"`
+	Title         string `json:"title"`
+	Slug          string `json:"slug"`
+	Content       string `json:"content"`
+	ContentFormat string `json:"content_format"`
+	ContentHTML   string `json:"content_html"` // Sanitized HTML rendered from Content
+	// Version of the renderer that produced ContentHTML; see ContentRenderer.Version
+	ContentHTMLVersion int       `json:"-"`
+	Photo              string    `json:"photo"`
+	TimeCreated        time.Time `json:"time_created"`
+	TimeUpdate         time.Time `json:"time_update"`
+	IsPublished        bool      `json:"is_published"`
+	CategoryID         uint      `json:"category_id"`
+	Category           *Category `json:"category,omitempty"` // Omitempty for optional eager loading
+	AuthorID           *uint     `json:"author_id"`          // Nil for posts written before authorship was tracked
+	Author             *Author   `json:"author,omitempty"`
+	Tags               []Tag     `json:"tags,omitempty" gorm:"many2many:blog_tags;"`
+}
+
+// Author is the public view of the user who wrote a post. Posts are served to
+// anyone, so it must not carry the email address or anything about the account.
+type Author struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// TableName maps Author onto the users table for eager loading.
+func (Author) TableName() string {
+	return "users"
 }
 
 // BlogRepository defines the interface for interacting with Blog data.
@@ -25,6 +44,13 @@ type BlogRepository interface {
 	FindBySlug(slug string) (*Blog, error)
 	FindAll(publishedOnly bool) ([]Blog, error)
 	FindByCategoryID(categoryID uint, publishedOnly bool) ([]Blog, error)
+	CountByCategoryID(categoryID uint) (int64, error)
+	Count(filter BlogFilter) (int64, error)
+	FindPage(filter BlogFilter, offset, limit int) ([]Blog, error)
+	FindPageByCursor(filter BlogFilter, cursor Cursor, limit int) ([]Blog, error)
+	Search(query string, page PageRequest) ([]SearchResult, int64, error)
+	FindSitemapPage(offset, limit int) ([]Blog, error)
 	Update(blog *Blog) error
+	UpdateContentHTML(id uint, contentHTML string, version int) error
 	Delete(id uint) error
 }
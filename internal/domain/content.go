@@ -0,0 +1,20 @@
+package domain
+
+// Formats a post body can be written in.
+const (
+	ContentFormatPlain    = "plain"    // Plain text, the only format before Markdown support
+	ContentFormatMarkdown = "markdown" // CommonMark with tables, footnotes and fenced code blocks
+)
+
+// IsValidContentFormat reports whether format is one of the supported content formats.
+func IsValidContentFormat(format string) bool {
+	return format == ContentFormatPlain || format == ContentFormatMarkdown
+}
+
+// ContentRenderer defines the interface for turning a post body into sanitized HTML.
+type ContentRenderer interface {
+	Render(content, format string) (string, error)
+	// Version changes whenever the same source would render differently, so
+	// HTML cached by an older version is rendered again.
+	Version() int
+}
@@ -4,8 +4,12 @@ import "errors"
 
 // Predefined errors for common domain scenarios.
 var (
-	ErrNotFound      = errors.New("not found")
-	ErrAlreadyExists = errors.New("already exists")
-	ErrInvalidInput  = errors.New("invalid input")
+	ErrNotFound         = errors.New("not found")
+	ErrAlreadyExists    = errors.New("already exists")
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrForbidden        = errors.New("forbidden")
+	ErrCategoryInUse    = errors.New("category still has posts")
+	ErrEmailNotVerified = errors.New("email address not verified")
 	// Add more domain-specific errors as needed
 )
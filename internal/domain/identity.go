@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// UserIdentity links a user to their account at an external identity provider,
+// so later logins find the user by the provider's stable subject identifier
+// rather than by email address, which can change.
+type UserIdentity struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"` // Address the provider reported when the link was made
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserIdentityRepository defines the interface for interacting with linked identities.
+type UserIdentityRepository interface {
+	FindBySubject(provider, subject string) (*UserIdentity, error)
+	Create(identity *UserIdentity) error
+}
+
+// ExternalIdentity is what an identity provider asserts about the person who
+// just logged in there, taken from a verified ID token.
+type ExternalIdentity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Nonce             string // Echoes the nonce sent with the authorization request
+}
+
+// IdentityProvider is an external login, e.g. the company's OpenID Connect
+// provider, using the authorization code flow with PKCE.
+type IdentityProvider interface {
+	// ID names the provider in URLs and in linked identities.
+	ID() string
+	// DisplayName is shown on the login button.
+	DisplayName() string
+	// AuthCodeURL returns where to send the browser to log in.
+	AuthCodeURL(state, nonce, codeChallenge string) (string, error)
+	// Exchange trades the authorization code for tokens and returns the identity
+	// from the verified ID token.
+	Exchange(code, codeVerifier string) (*ExternalIdentity, error)
+}
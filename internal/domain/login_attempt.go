@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// LoginFailures counts the recent failed logins for one key: an account, keyed by
+// username so that unknown usernames are treated the same, or a client address.
+// Attempts are counted before the credentials are checked and taken back when they
+// were right, so the count includes logins in progress.
+type LoginFailures struct {
+	Key          string     `json:"key" gorm:"column:throttle_key;primaryKey"`
+	Failures     int        `json:"failures"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+}
+
+// LoginAttemptRepository defines the interface for tracking failed logins.
+type LoginAttemptRepository interface {
+	// ReserveAttempt counts an attempt for the key unless it is locked at the given
+	// time, and then locks it for lockout(count) if that is positive. Counting starts
+	// over when the previous attempt is older than resetAfter. The lock is checked
+	// and the attempt counted atomically. It reports whether the attempt may go
+	// ahead and whether it locked the key. Keys whose failures no longer count and
+	// whose lock is over may be forgotten along the way.
+	ReserveAttempt(key string, at time.Time, resetAfter time.Duration, lockout func(count int) time.Duration) (allowed, locked bool, err error)
+	// ReleaseAttempt takes back a reserved attempt, and lifts the lock it started
+	// if unlock is set.
+	ReleaseAttempt(key string, unlock bool) error
+	// Reset forgets the key's failures and lifts its lock.
+	Reset(key string) error
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page sizes for post listings.
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// BlogFilter narrows down which posts a listing returns.
+type BlogFilter struct {
+	PublishedOnly bool
+	CategoryID    uint // Zero means any category
+	TagID         uint // Zero means any tag
+}
+
+// PageRequest asks for one page of a listing, either by page number (offset)
+// or by cursor (keyset). A non-empty Cursor takes precedence over Page.
+type PageRequest struct {
+	Page    int
+	PerPage int
+	Cursor  string
+}
+
+// Cursor marks a position in a listing ordered by (time_created DESC, id DESC).
+type Cursor struct {
+	TimeCreated time.Time
+	ID          uint
+	Before      bool // Walk towards newer posts, i.e. fetch the previous page
+}
+
+// CursorFor returns the cursor pointing at the given post.
+func CursorFor(blog Blog, before bool) Cursor {
+	return Cursor{TimeCreated: blog.TimeCreated, ID: blog.ID, Before: before}
+}
+
+// Encode serializes the cursor into an opaque URL-safe token.
+func (c Cursor) Encode() string {
+	direction := "a"
+	if c.Before {
+		direction = "b"
+	}
+	raw := fmt.Sprintf("%d:%d:%s", c.TimeCreated.UnixNano(), c.ID, direction)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode.
+func DecodeCursor(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidInput
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 || (parts[2] != "a" && parts[2] != "b") {
+		return Cursor{}, ErrInvalidInput
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidInput
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidInput
+	}
+	return Cursor{TimeCreated: time.Unix(0, nanos), ID: uint(id), Before: parts[2] == "b"}, nil
+}
+
+// BlogPage is one page of posts along with what is needed to link to its neighbours.
+// Offset pages fill PrevPage/NextPage, cursor pages fill PrevCursor/NextCursor.
+type BlogPage struct {
+	Posts      []Blog `json:"posts"`
+	Total      int64  `json:"total"`
+	PerPage    int    `json:"per_page"`
+	TotalPages int    `json:"total_pages"`
+	Page       int    `json:"page,omitempty"` // Zero for cursor pages
+	PrevPage   int    `json:"prev_page,omitempty"`
+	NextPage   int    `json:"next_page,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+// PasswordHasher turns passwords into the hashes stored with users and checks
+// passwords against them.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the hash. The error is for
+	// hashes that cannot be read, not for wrong passwords.
+	Verify(password, hash string) (bool, error)
+	// NeedsRehash reports whether the hash was made with another algorithm or
+	// weaker parameters than new hashes get, so it should be replaced the next
+	// time the password is known.
+	NeedsRehash(hash string) bool
+}
+
+// PasswordBlocklist holds passwords too well known to be allowed, e.g. from
+// breach corpora.
+type PasswordBlocklist interface {
+	Contains(password string) bool
+}
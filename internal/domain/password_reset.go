@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// PasswordResetToken is an outstanding "forgot password" link. The token itself is
+// only ever sent to the user; the database keeps its hash.
+type PasswordResetToken struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	TokenHash string    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PasswordResetRepository defines the interface for interacting with password reset tokens.
+type PasswordResetRepository interface {
+	Create(token *PasswordResetToken) error
+	// FindByHash returns the token with the given hash, expired or not, without
+	// using it up. It returns nil, nil for unknown tokens.
+	FindByHash(tokenHash string) (*PasswordResetToken, error)
+	// Redeem uses up the token and, in the same transaction, stores the user's
+	// password, session version and email verification and deletes the user's API
+	// tokens and other reset tokens. It reports false, having changed nothing, if
+	// the token is gone or expired at now, so it works only once even for
+	// concurrent requests.
+	Redeem(tokenID uint, user *User, now time.Time) (bool, error)
+	DeleteByUserID(userID uint) error
+}
@@ -0,0 +1,57 @@
+package domain
+
+// Role is the access level granted to a user.
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleAuthor Role = "author"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// Permission names a single action guarded by role checks.
+type Permission string
+
+const (
+	PermissionWritePosts       Permission = "posts:write"   // Create posts and edit own posts
+	PermissionPublishPosts     Permission = "posts:publish" // Publish or unpublish posts
+	PermissionEditAnyPost      Permission = "posts:edit_any"
+	PermissionManageCategories Permission = "categories:manage"
+	PermissionManageUsers      Permission = "users:manage"
+)
+
+// IsValid reports whether p is one of the known permissions.
+func (p Permission) IsValid() bool {
+	return RoleAdmin.Can(p) // Admins hold every permission
+}
+
+// rolePermissions lists what each role may do. Readers have no write permissions.
+var rolePermissions = map[Role][]Permission{
+	RoleReader: {},
+	RoleAuthor: {PermissionWritePosts},
+	RoleEditor: {PermissionWritePosts, PermissionPublishPosts, PermissionEditAnyPost},
+	RoleAdmin: {
+		PermissionWritePosts,
+		PermissionPublishPosts,
+		PermissionEditAnyPost,
+		PermissionManageCategories,
+		PermissionManageUsers,
+	},
+}
+
+// IsValid reports whether r is one of the known roles.
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants the given permission.
+func (r Role) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
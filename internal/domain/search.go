@@ -0,0 +1,29 @@
+package domain
+
+// Markers the repository wraps around matched words in search snippets.
+// They are private-use code points, so they never collide with post text and
+// survive HTML escaping unchanged.
+const (
+	SearchHighlightStart = "\ue000"
+	SearchHighlightStop  = "\ue001"
+)
+
+// SearchResult is a post matching a search query.
+type SearchResult struct {
+	Post        Blog    `json:"post"`
+	Rank        float64 `json:"rank"`
+	Snippet     string  `json:"-"`       // Excerpt with matches wrapped in the highlight markers
+	SnippetHTML string  `json:"snippet"` // Escaped excerpt with matches wrapped in <mark>
+}
+
+// SearchPage is one page of search results, best matches first.
+type SearchPage struct {
+	Query      string         `json:"query"`
+	Results    []SearchResult `json:"results"`
+	Total      int64          `json:"total"`
+	PerPage    int            `json:"per_page"`
+	TotalPages int            `json:"total_pages"`
+	Page       int            `json:"page"`
+	PrevPage   int            `json:"prev_page,omitempty"`
+	NextPage   int            `json:"next_page,omitempty"`
+}
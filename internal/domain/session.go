@@ -0,0 +1,91 @@
+package domain
+
+import "time"
+
+// AccessClaims is what a short-lived access token asserts about its bearer.
+type AccessClaims struct {
+	TokenID        string // Unique per token (the JWT "jti"), used to revoke it before it expires
+	UserID         uint
+	Username       string
+	Role           Role
+	SessionVersion int // Must match User.SessionVersion
+	ExpiresAt      time.Time
+	// Set for API tokens: the request may only use these permissions of the role.
+	// Nil for login sessions, which have the role's full permissions.
+	Scopes TokenScopes
+}
+
+// Allows reports whether the token's scopes cover the permission. The role has to
+// grant it as well.
+func (c *AccessClaims) Allows(permission Permission) bool {
+	return c.Scopes == nil || c.Scopes.Contains(permission)
+}
+
+// AccessTokenService issues and verifies signed access tokens.
+type AccessTokenService interface {
+	Issue(user *User) (token string, claims *AccessClaims, err error)
+	// Parse verifies the token's signature and expiry.
+	Parse(token string) (*AccessClaims, error)
+}
+
+// JSONWebKey is the public half of a token signing key in RFC 7517 form.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // Ed25519
+	X         string `json:"x,omitempty"`   // Ed25519 public key
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PublicKeyProvider exposes the public keys other services need to verify
+// access tokens themselves. Symmetric keys are never published.
+type PublicKeyProvider interface {
+	PublicKeys() JSONWebKeySet
+}
+
+// TokenPair is what a client receives when a session starts or is refreshed.
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	AccessExpiresAt  time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// RefreshToken lets a client obtain new access tokens without the password. Each
+// refresh rotates it: the old token is marked used and a new one in the same family
+// is issued, so presenting a used token again reveals that it was stolen.
+type RefreshToken struct {
+	ID             uint       `json:"id"`
+	UserID         uint       `json:"user_id"`
+	TokenHash      string     `json:"-"`
+	FamilyID       string     `json:"family_id"` // Shared by all tokens rotated from one login
+	SessionVersion int        `json:"-"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	UsedAt         *time.Time `json:"used_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// RefreshTokenRepository defines the interface for interacting with refresh tokens.
+type RefreshTokenRepository interface {
+	Create(token *RefreshToken) error
+	FindByHash(tokenHash string) (*RefreshToken, error)
+	// MarkUsed marks the token as rotated. It reports false if the token was already
+	// used or revoked, e.g. by a concurrent request.
+	MarkUsed(id uint, at time.Time) (bool, error)
+	RevokeFamily(familyID string, at time.Time) error
+}
+
+// TokenDenylist remembers access tokens revoked before their expiry.
+type TokenDenylist interface {
+	Add(tokenID string, expiresAt time.Time) error
+	Contains(tokenID string) (bool, error)
+}
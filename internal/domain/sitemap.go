@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// SitemapMaxURLs is the most URLs the sitemap protocol allows in a single sitemap file.
+const SitemapMaxURLs = 50000
+
+// SitemapEntry is one URL listed in a sitemap.
+type SitemapEntry struct {
+	Path    string    // Site-relative path, e.g. /post/hello-world
+	LastMod time.Time // Zero if unknown
+}
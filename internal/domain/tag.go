@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Tag is a free-form label; unlike categories, a post can carry many tags.
+type Tag struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TagCount is a tag together with the number of published posts carrying it.
+type TagCount struct {
+	Tag
+	PostCount int64 `json:"post_count"`
+	Weight    int   `json:"weight" gorm:"-"` // 1 (rare) to 5 (popular), for sizing the tag cloud
+}
+
+// TagRepository defines the interface for interacting with Tag data.
+type TagRepository interface {
+	Create(tag *Tag) error
+	FindByID(id uint) (*Tag, error)
+	FindBySlug(slug string) (*Tag, error)
+	FindAll() ([]Tag, error)
+	FindPublishedCounts() ([]TagCount, error)
+	Delete(id uint) error
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// RecoveryCode is a one-time code that stands in for a TOTP code when the user
+// has lost their authenticator. The database keeps only its hash.
+type RecoveryCode struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RecoveryCodeRepository defines the interface for interacting with recovery codes.
+type RecoveryCodeRepository interface {
+	// ReplaceAll deletes the user's codes and stores the given hashes instead.
+	ReplaceAll(userID uint, codeHashes []string) error
+	// Consume marks an unused code of the user as used at the given time; false
+	// means there was no such code. Concurrent requests cannot both consume it.
+	Consume(userID uint, codeHash string, at time.Time) (bool, error)
+	DeleteByUserID(userID uint) error
+}
+
+// SecretCipher encrypts secrets that must be stored but read back later, like
+// TOTP keys, so a database dump alone does not reveal them.
+type SecretCipher interface {
+	Encrypt(plaintext []byte) (string, error)
+	Decrypt(ciphertext string) ([]byte, error)
+}
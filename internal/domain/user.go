@@ -7,9 +7,31 @@ type User struct {
 	ID        uint      `json:"id"`
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
-	Password  string    `json:"-"` // Exclude from JSON output
+	Password  string    `json:"-" gorm:"column:password_hash"` // Exclude from JSON output
+	Role      Role      `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Set once the user followed the link emailed to them; nil while unconfirmed
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// When the last verification link was emailed, to limit how often one is resent
+	VerificationSentAt *time.Time `json:"-"`
+	// Issued tokens carry this number; incrementing it signs the user out everywhere
+	SessionVersion int `json:"-" gorm:"default:1"`
+	// Encrypted TOTP secret; set but not yet enabled while enrollment awaits confirmation
+	TOTPSecret    string     `json:"-" gorm:"column:totp_secret"`
+	TOTPEnabledAt *time.Time `json:"-" gorm:"column:totp_enabled_at"`
+	// Last accepted TOTP time step, so a code works only once
+	TOTPLastStep int64 `json:"-" gorm:"column:totp_last_step"`
+}
+
+// IsEmailVerified reports whether the user has confirmed their email address.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsTwoFactorEnabled reports whether logging in requires a TOTP code.
+func (u *User) IsTwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil && u.TOTPSecret != ""
 }
 
 // UserRepository defines the interface for interacting with User data.
@@ -19,5 +41,11 @@ type UserRepository interface {
 	FindByUsername(username string) (*User, error)
 	FindByEmail(email string) (*User, error)
 	Update(user *User) error
+	// AdvanceTOTPStep records step as the user's last used TOTP step unless an equal
+	// or later one was recorded already; false means the code was used before.
+	AdvanceTOTPStep(userID uint, step int64) (bool, error)
+	// ClaimVerificationEmail records at as the time the last verification link was
+	// sent unless one went out less than cooldown earlier; false means it did.
+	ClaimVerificationEmail(userID uint, at time.Time, cooldown time.Duration) (bool, error)
 	Delete(id uint) error
 }
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"programming_blog_go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCSRFTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CSRFMiddleware(false))
+	handler := func(c *gin.Context) {
+		token, _ := c.Get("csrf_token")
+		c.String(http.StatusOK, "%v", token)
+	}
+	r.GET("/form", handler)
+	r.POST("/submit", handler)
+	return r
+}
+
+func TestCSRFMiddleware_IssuesTokenOnGet(t *testing.T) {
+	r := newCSRFTestRouter()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	cookies := w.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, utils.CSRFCookie, cookies[0].Name)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.Equal(t, cookies[0].Value, w.Body.String())
+	}
+}
+
+func TestCSRFMiddleware_UnsafeRequests(t *testing.T) {
+	form := func(token string) string {
+		return url.Values{utils.CSRFFormField: {token}, "title": {"x"}}.Encode()
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		headers     map[string]string
+		cookies     map[string]string
+		wantStatus  int
+	}{
+		{
+			name:        "form with matching token",
+			contentType: "application/x-www-form-urlencoded",
+			body:        form("secret"),
+			cookies:     map[string]string{utils.CSRFCookie: "secret"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "form with wrong token",
+			contentType: "application/x-www-form-urlencoded",
+			body:        form("guess"),
+			cookies:     map[string]string{utils.CSRFCookie: "secret"},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "form without CSRF cookie",
+			contentType: "application/x-www-form-urlencoded",
+			body:        form(""),
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "cookie session with header token",
+			contentType: "application/json",
+			body:        `{}`,
+			headers:     map[string]string{utils.CSRFHeader: "secret"},
+			cookies:     map[string]string{utils.CSRFCookie: "secret", utils.AccessTokenCookie: "jwt"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "cookie session without token",
+			contentType: "application/json",
+			body:        `{}`,
+			cookies:     map[string]string{utils.CSRFCookie: "secret", utils.AccessTokenCookie: "jwt"},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "bearer client",
+			contentType: "application/x-www-form-urlencoded",
+			body:        form(""),
+			headers:     map[string]string{"Authorization": "Bearer jwt"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "JSON client without cookies",
+			contentType: "application/json",
+			body:        `{"username":"alice"}`,
+			wantStatus:  http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newCSRFTestRouter()
+			req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			for name, value := range tt.cookies {
+				req.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"programming_blog_go/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdentityProviderContextMiddleware adds the external login providers to the Gin
+// context, so the login page can offer a button for each.
+func IdentityProviderContextMiddleware(providers []domain.IdentityProvider) gin.HandlerFunc {
+	links := make([]gin.H, len(providers))
+	for i, provider := range providers {
+		links[i] = gin.H{"id": provider.ID(), "name": provider.DisplayName()}
+	}
+	return func(c *gin.Context) {
+		c.Set("identity_providers", links)
+		c.Next()
+	}
+}
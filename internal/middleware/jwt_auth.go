@@ -1,62 +1,214 @@
 package middleware
 
 import (
-	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"strings"
-	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/usecase"
+	"programming_blog_go/internal/utils"
+
 	"github.com/gin-gonic/gin"
 )
 
-// JWTAuthMiddleware validates the JWT token from the Authorization header.
-// It now takes the jwtSecret as an argument.
-func JWTAuthMiddleware(jwtSecret []byte) gin.HandlerFunc {
+// Ways a request can be authenticated, stored in the context under "auth_method".
+const (
+	AuthMethodBearer   = "bearer"
+	AuthMethodCookie   = "cookie"
+	AuthMethodAPIToken = "api_token"
+)
+
+// authError is a reason to reject a request as unauthenticated; its message is
+// what API clients see.
+type authError struct{ message string }
+
+func (e *authError) Error() string { return e.message }
+
+var (
+	errNoCredentials      = &authError{"Authorization header required"}
+	errInvalidTokenFormat = &authError{"Invalid token format"}
+	errInvalidToken       = &authError{"Invalid token"}
+	errTokenRevoked       = &authError{"Token revoked"}
+	errSessionExpired     = &authError{"Session expired"}
+	errEmailNotVerified   = &authError{"Email address not verified"}
+)
+
+// Authenticator identifies the user behind a request, either from an
+// Authorization: Bearer header (API clients, with a JWT or a personal API token)
+// or from the session cookies set by the browser login. Besides the signature and expiry, an access token must not
+// have been revoked by logout and must match the user's current session version,
+// so a password reset signs out every session.
+type Authenticator struct {
+	Tokens   domain.AccessTokenService
+	Users    domain.UserRepository
+	Denylist domain.TokenDenylist
+	// Verifies personal API tokens, recognized by usecase.APITokenPrefix
+	APITokens *usecase.AuthenticateAPITokenUseCase
+	// Renews a browser session whose access cookie has expired
+	Refresh       *usecase.RefreshSessionUseCase
+	SecureCookies bool
+}
+
+// authenticate returns the claims of the request's access token. An *authError
+// means the request carries no usable credentials; other errors are failures.
+func (a *Authenticator) authenticate(c *gin.Context) (*domain.AccessClaims, string, error) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		// Expected format: "Bearer <token>"
+		parts := strings.Split(header, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return nil, "", errInvalidTokenFormat
+		}
+		if strings.HasPrefix(parts[1], usecase.APITokenPrefix) {
+			claims, err := a.verifyAPIToken(parts[1])
+			return claims, AuthMethodAPIToken, err
+		}
+		claims, err := a.verify(parts[1])
+		return claims, AuthMethodBearer, err
+	}
+
+	accessToken, _ := c.Cookie(utils.AccessTokenCookie)
+	refreshToken, _ := c.Cookie(utils.RefreshTokenCookie)
+	if accessToken == "" && refreshToken == "" {
+		return nil, "", errNoCredentials
+	}
+	if accessToken != "" {
+		claims, err := a.verify(accessToken)
+		if _, rejected := err.(*authError); !rejected || refreshToken == "" {
+			return claims, AuthMethodCookie, err
+		}
+	}
+
+	// The access cookie is gone or stale: renew the session transparently. Requests
+	// racing with the same cookie share one rotation (see RefreshSessionUseCase).
+	pair, err := a.Refresh.Execute(usecase.RefreshSessionRequest{RefreshToken: refreshToken})
+	if err == usecase.ErrInvalidRefreshToken || err == domain.ErrEmailNotVerified {
+		utils.ClearSessionCookies(c, a.SecureCookies)
+		return nil, "", errSessionExpired
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	claims, err := a.Tokens.Parse(pair.AccessToken)
+	if err != nil {
+		return nil, "", err
+	}
+	utils.SetSessionCookies(c, pair, a.SecureCookies)
+	return claims, AuthMethodCookie, nil
+}
+
+// verify checks an access token against the denylist and the user's session version.
+func (a *Authenticator) verify(token string) (*domain.AccessClaims, error) {
+	claims, err := a.Tokens.Parse(token)
+	if err != nil {
+		return nil, errInvalidToken
+	}
+
+	revoked, err := a.Denylist.Contains(claims.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errTokenRevoked
+	}
+
+	user, err := a.Users.FindByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.SessionVersion != claims.SessionVersion {
+		return nil, errSessionExpired
+	}
+	return claims, nil
+}
+
+// verifyAPIToken checks a personal API token.
+func (a *Authenticator) verifyAPIToken(token string) (*domain.AccessClaims, error) {
+	claims, err := a.APITokens.Execute(token)
+	switch err {
+	case nil:
+		return claims, nil
+	case usecase.ErrInvalidAPIToken:
+		return nil, errInvalidToken
+	case domain.ErrEmailNotVerified:
+		return nil, errEmailNotVerified
+	default:
+		return nil, err
+	}
+}
+
+// setIdentity puts the authenticated user into the context for handlers and templates.
+func setIdentity(c *gin.Context, claims *domain.AccessClaims, method string) {
+	utils.SetAccessClaims(c, claims)
+	c.Set("auth_method", method)
+}
+
+// JWTAuthMiddleware protects API routes, answering 401 JSON when the request
+// carries neither a valid Bearer token nor a valid session cookie.
+func JWTAuthMiddleware(auth *Authenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+		claims, method, err := auth.authenticate(c)
+		if rejected, ok := err.(*authError); ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": rejected.message})
 			c.Abort()
 			return
 		}
-
-		// Expected format: "Bearer <token>"
-		parts := strings.Split(tokenString, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "An unexpected error occurred"})
 			c.Abort()
 			return
 		}
-		tokenString = parts[1]
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return jwtSecret, nil // Use the passed secret
-		})
+		setIdentity(c, claims, method)
+		c.Next()
+	}
+}
 
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+// PageAuthMiddleware protects HTML pages, sending visitors who are not logged in
+// to the login form and back to the page afterwards.
+func PageAuthMiddleware(auth *Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, method, err := auth.authenticate(c)
+		if _, ok := err.(*authError); ok {
+			c.Redirect(http.StatusFound, "/login?next="+url.QueryEscape(c.Request.URL.RequestURI()))
 			c.Abort()
 			return
 		}
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		setIdentity(c, claims, method)
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware identifies the visitor when possible, e.g. for the
+// navigation in base.html, and lets anonymous requests through.
+func OptionalAuthMiddleware(auth *Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, method, err := auth.authenticate(c)
+		if err == nil {
+			setIdentity(c, claims, method)
+		} else if _, ok := err.(*authError); !ok {
+			log.Printf("Error authenticating request: %v", err)
+		}
+		c.Next()
+	}
+}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Check token expiration
-			if float64(time.Now().Unix()) > claims["exp"].(float64) {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
-				c.Abort()
-				return
-			}
-			// Set user information in context
-			c.Set("user_id", claims["user_id"])
-			c.Set("username", claims["username"])
-			c.Next()
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+// RejectAPITokenMiddleware keeps personal API tokens away from routes that manage
+// the account itself, like logout, 2FA and the tokens, which need a login session.
+// It must run after JWTAuthMiddleware.
+func RejectAPITokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") == AuthMethodAPIToken {
+			c.JSON(http.StatusForbidden, gin.H{"error": usecase.ErrAPITokenNotPermitted.Error()})
 			c.Abort()
+			return
 		}
+		c.Next()
 	}
 }
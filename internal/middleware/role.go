@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole allows the request through only if the authenticated user has one of the given roles.
+// It must run after JWTAuthMiddleware, which puts the role into the context.
+func RequireRole(roles ...domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := utils.GetRoleFromContext(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient role"})
+		c.Abort()
+	}
+}
+
+// RequirePermission allows the request through only if the authenticated user's role grants the permission
+// and, for API tokens, the token's scopes include it.
+// It must run after JWTAuthMiddleware, which puts the role into the context.
+func RequirePermission(permission domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !utils.HasPermission(c, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"log"
+	"programming_blog_go/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagContextMiddleware fetches the tag cloud and adds it to the Gin context.
+func TagContextMiddleware(getTagCloudUC *usecase.GetTagCloudUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("tags", []interface{}{}) // Initialize with empty slice to avoid nil pointer issues in templates
+
+		tags, err := getTagCloudUC.Execute()
+		if err != nil {
+			log.Printf("Error fetching tag cloud for context: %v", err)
+			// Continue processing request even if tags can't be fetched
+		} else {
+			// Convert domain.TagCount to interface{} for template consumption
+			interfaceTags := make([]interface{}, len(tags))
+			for i, tag := range tags {
+				interfaceTags[i] = tag
+			}
+			c.Set("tags", interfaceTags)
+		}
+		c.Next()
+	}
+}
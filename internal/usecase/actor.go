@@ -0,0 +1,35 @@
+package usecase
+
+import "programming_blog_go/internal/domain"
+
+// Actor identifies the authenticated user on whose behalf a use case runs.
+type Actor struct {
+	UserID uint
+	Role   domain.Role
+	// Set when the request came with an API token; nil for login sessions
+	Scopes domain.TokenScopes
+}
+
+// Can reports whether the actor's role grants the given permission and, for API
+// tokens, whether the token's scopes include it.
+func (a Actor) Can(permission domain.Permission) bool {
+	if !a.Role.Can(permission) {
+		return false
+	}
+	return !a.isAPIToken() || a.Scopes.Contains(permission)
+}
+
+// isAPIToken reports whether the actor authenticated with an API token rather
+// than by logging in.
+func (a Actor) isAPIToken() bool {
+	return a.Scopes != nil
+}
+
+// canModifyPost reports whether the actor may edit or delete the given post:
+// authors may change their own posts, editors and admins may change any post.
+func (a Actor) canModifyPost(post *domain.Blog) bool {
+	if a.Can(domain.PermissionEditAnyPost) {
+		return true
+	}
+	return a.Can(domain.PermissionWritePosts) && post.AuthorID != nil && *post.AuthorID == a.UserID
+}
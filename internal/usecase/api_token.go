@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"programming_blog_go/internal/domain"
+)
+
+var (
+	ErrInvalidAPIToken      = errors.New("invalid or expired API token")
+	ErrAPITokenLimitReached = errors.New("too many API tokens")
+	ErrAPITokenNotPermitted = errors.New("API tokens cannot manage the account")
+)
+
+// APITokenPrefix starts every API token, so the auth middleware can tell them from
+// JWTs and secret scanners can recognize leaked ones.
+const APITokenPrefix = "blog_pat_"
+
+const (
+	DefaultAPITokenTTL = 30 * 24 * time.Hour
+	MaxAPITokenTTL     = 365 * 24 * time.Hour
+	MaxAPITokens       = 20 // Per user
+	// How many characters of a token are kept in clear to tell tokens apart in the list
+	apiTokenDisplayLength = len(APITokenPrefix) + 6
+	// Last use is recorded at most this often, not on every request
+	apiTokenLastUsedResolution = time.Minute
+)
+
+// CreateAPITokenUseCase creates a personal access token for the logged-in user.
+type CreateAPITokenUseCase struct {
+	APITokenRepository domain.APITokenRepository
+}
+
+type CreateAPITokenRequest struct {
+	Name   string   `json:"name" form:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" form:"scopes" binding:"required"`
+	// Defaults to DefaultAPITokenTTL; at most MaxAPITokenTTL
+	ExpiresInDays int `json:"expires_in_days" form:"expires_in_days" binding:"omitempty,min=1"`
+}
+
+// CreatedAPIToken is the response to creating a token: the only time the token
+// itself is returned.
+type CreatedAPIToken struct {
+	Token string `json:"token"`
+	*domain.APIToken
+}
+
+func (uc *CreateAPITokenUseCase) Execute(actor Actor, req CreateAPITokenRequest) (*CreatedAPIToken, error) {
+	if actor.UserID == 0 {
+		return nil, domain.ErrUnauthorized
+	}
+	// A leaked token must not be able to mint more tokens for itself
+	if actor.isAPIToken() {
+		return nil, ErrAPITokenNotPermitted
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	scopes, err := parseScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	// Only what the user may do themselves
+	for _, scope := range scopes {
+		if !actor.Can(scope) {
+			return nil, domain.ErrForbidden
+		}
+	}
+	ttl := DefaultAPITokenTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl > MaxAPITokenTTL {
+		return nil, domain.ErrInvalidInput
+	}
+
+	existing, err := uc.APITokenRepository.ListByUserID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxAPITokens {
+		return nil, ErrAPITokenLimitReached
+	}
+
+	secret, _, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	token := APITokenPrefix + secret
+	now := time.Now()
+	stored := &domain.APIToken{
+		UserID:    actor.UserID,
+		Name:      name,
+		TokenHash: hashToken(token),
+		Prefix:    token[:apiTokenDisplayLength],
+		Scopes:    scopes,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := uc.APITokenRepository.Create(stored); err != nil {
+		return nil, err
+	}
+	return &CreatedAPIToken{Token: token, APIToken: stored}, nil
+}
+
+// parseScopes checks that every scope names a known permission, like "posts:write",
+// and drops duplicates.
+func parseScopes(names []string) (domain.TokenScopes, error) {
+	scopes := domain.TokenScopes{}
+	for _, name := range names {
+		scope := domain.Permission(strings.TrimSpace(name))
+		if !scope.IsValid() {
+			return nil, domain.ErrInvalidInput
+		}
+		if !scopes.Contains(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+	return scopes, nil
+}
+
+// ListAPITokensUseCase lists the logged-in user's tokens without their values.
+type ListAPITokensUseCase struct {
+	APITokenRepository domain.APITokenRepository
+}
+
+func (uc *ListAPITokensUseCase) Execute(actor Actor) ([]domain.APIToken, error) {
+	if actor.UserID == 0 {
+		return nil, domain.ErrUnauthorized
+	}
+	if actor.isAPIToken() {
+		return nil, ErrAPITokenNotPermitted
+	}
+	return uc.APITokenRepository.ListByUserID(actor.UserID)
+}
+
+// RevokeAPITokenUseCase deletes one of the logged-in user's tokens; it stops
+// working immediately.
+type RevokeAPITokenUseCase struct {
+	APITokenRepository domain.APITokenRepository
+}
+
+func (uc *RevokeAPITokenUseCase) Execute(actor Actor, id uint) error {
+	if actor.UserID == 0 {
+		return domain.ErrUnauthorized
+	}
+	if actor.isAPIToken() {
+		return ErrAPITokenNotPermitted
+	}
+	deleted, err := uc.APITokenRepository.Delete(id, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// AuthenticateAPITokenUseCase turns an API token from an Authorization header into
+// access claims. The role comes from the user as they are now, so a demotion
+// applies to existing tokens, and the token's scopes limit it further.
+type AuthenticateAPITokenUseCase struct {
+	APITokenRepository domain.APITokenRepository
+	UserRepository     domain.UserRepository
+	UnverifiedPolicy   UnverifiedLoginPolicy // Same policy as at login
+}
+
+func (uc *AuthenticateAPITokenUseCase) Execute(token string) (*domain.AccessClaims, error) {
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return nil, ErrInvalidAPIToken
+	}
+	stored, err := uc.APITokenRepository.FindByHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if stored == nil || !stored.ExpiresAt.After(now) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	user, err := uc.UserRepository.FindByID(stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidAPIToken
+	}
+	if err := applyUnverifiedPolicy(uc.UnverifiedPolicy, user); err != nil {
+		return nil, err
+	}
+
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= apiTokenLastUsedResolution {
+		if err := uc.APITokenRepository.TouchLastUsed(stored.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	scopes := stored.Scopes
+	if scopes == nil {
+		scopes = domain.TokenScopes{} // Never nil, which would mean unrestricted
+	}
+	return &domain.AccessClaims{
+		TokenID:        fmt.Sprintf("api-token:%d", stored.ID),
+		UserID:         user.ID,
+		Username:       user.Username,
+		Role:           user.Role,
+		SessionVersion: user.SessionVersion,
+		ExpiresAt:      stored.ExpiresAt,
+		Scopes:         scopes,
+	}, nil
+}
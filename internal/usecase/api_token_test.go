@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPITokenRepository is a mock implementation of domain.APITokenRepository
+type MockAPITokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPITokenRepository) Create(token *domain.APIToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockAPITokenRepository) FindByHash(tokenHash string) (*domain.APIToken, error) {
+	args := m.Called(tokenHash)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.APIToken), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) ListByUserID(userID uint) ([]domain.APIToken, error) {
+	args := m.Called(userID)
+	tokens, _ := args.Get(0).([]domain.APIToken)
+	return tokens, args.Error(1)
+}
+
+func (m *MockAPITokenRepository) Delete(id, userID uint) (bool, error) {
+	args := m.Called(id, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) DeleteByUserID(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockAPITokenRepository) TouchLastUsed(id uint, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func TestActor_Can_Scopes(t *testing.T) {
+	session := Actor{UserID: 1, Role: domain.RoleEditor}
+	assert.True(t, session.Can(domain.PermissionPublishPosts))
+
+	token := Actor{UserID: 1, Role: domain.RoleEditor, Scopes: domain.TokenScopes{domain.PermissionWritePosts}}
+	assert.True(t, token.Can(domain.PermissionWritePosts))
+	assert.False(t, token.Can(domain.PermissionPublishPosts), "not in the scopes")
+
+	// Scopes never exceed the role
+	author := Actor{UserID: 1, Role: domain.RoleAuthor, Scopes: domain.TokenScopes{domain.PermissionPublishPosts}}
+	assert.False(t, author.Can(domain.PermissionPublishPosts))
+}
+
+func TestCreateAPITokenUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockAPITokenRepository)
+	usecase := &CreateAPITokenUseCase{APITokenRepository: mockRepo}
+	author := Actor{UserID: 1, Role: domain.RoleAuthor}
+
+	// Test case: Success
+	mockRepo.On("ListByUserID", uint(1)).Return([]domain.APIToken{}, nil).Once()
+	var stored *domain.APIToken
+	mockRepo.On("Create", mock.AnythingOfType("*domain.APIToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*domain.APIToken)
+	}).Return(nil).Once()
+
+	created, err := usecase.Execute(author, CreateAPITokenRequest{
+		Name:          "CI",
+		Scopes:        []string{"posts:write", "posts:write"},
+		ExpiresInDays: 7,
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Token, APITokenPrefix))
+	// Only the hash is stored, with enough of a prefix to recognize the token
+	assert.Equal(t, hashToken(created.Token), stored.TokenHash)
+	assert.True(t, strings.HasPrefix(created.Token, stored.Prefix))
+	assert.Equal(t, domain.TokenScopes{domain.PermissionWritePosts}, stored.Scopes)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), stored.ExpiresAt, time.Minute)
+
+	// Test case: A scope the role does not grant
+	_, err = usecase.Execute(author, CreateAPITokenRequest{Name: "CI", Scopes: []string{"posts:publish"}})
+	assert.Equal(t, domain.ErrForbidden, err)
+
+	// Test case: Unknown scope
+	_, err = usecase.Execute(author, CreateAPITokenRequest{Name: "CI", Scopes: []string{"posts:everything"}})
+	assert.Equal(t, domain.ErrInvalidInput, err)
+
+	// Test case: Expiry too far away
+	_, err = usecase.Execute(author, CreateAPITokenRequest{Name: "CI", Scopes: []string{"posts:write"}, ExpiresInDays: 366})
+	assert.Equal(t, domain.ErrInvalidInput, err)
+
+	// Test case: An API token cannot create tokens
+	scoped := Actor{UserID: 1, Role: domain.RoleAuthor, Scopes: domain.TokenScopes{domain.PermissionWritePosts}}
+	_, err = usecase.Execute(scoped, CreateAPITokenRequest{Name: "CI", Scopes: []string{"posts:write"}})
+	assert.Equal(t, ErrAPITokenNotPermitted, err)
+
+	// Test case: Limit reached
+	mockRepo.On("ListByUserID", uint(1)).Return(make([]domain.APIToken, MaxAPITokens), nil).Once()
+	_, err = usecase.Execute(author, CreateAPITokenRequest{Name: "CI", Scopes: []string{"posts:write"}})
+	assert.Equal(t, ErrAPITokenLimitReached, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRevokeAPITokenUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockAPITokenRepository)
+	usecase := &RevokeAPITokenUseCase{APITokenRepository: mockRepo}
+	actor := Actor{UserID: 1, Role: domain.RoleAuthor}
+
+	mockRepo.On("Delete", uint(5), uint(1)).Return(true, nil).Once()
+	assert.NoError(t, usecase.Execute(actor, 5))
+
+	// Test case: Someone else's token or no such token
+	mockRepo.On("Delete", uint(6), uint(1)).Return(false, nil).Once()
+	assert.Equal(t, domain.ErrNotFound, usecase.Execute(actor, 6))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuthenticateAPITokenUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockAPITokenRepository)
+	mockUsers := new(MockUserRepository)
+	usecase := &AuthenticateAPITokenUseCase{
+		APITokenRepository: mockRepo,
+		UserRepository:     mockUsers,
+		UnverifiedPolicy:   UnverifiedLoginReject,
+	}
+	token := APITokenPrefix + "secret"
+	verifiedAt := time.Now()
+	user := &domain.User{ID: 1, Username: "ci", Role: domain.RoleEditor, SessionVersion: 3, EmailVerifiedAt: &verifiedAt}
+	stored := &domain.APIToken{
+		ID:        9,
+		UserID:    1,
+		Scopes:    domain.TokenScopes{domain.PermissionWritePosts},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	// Test case: Valid token; the first use is recorded
+	mockRepo.On("FindByHash", hashToken(token)).Return(stored, nil).Once()
+	mockUsers.On("FindByID", uint(1)).Return(user, nil).Once()
+	mockRepo.On("TouchLastUsed", uint(9), mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	claims, err := usecase.Execute(token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), claims.UserID)
+	assert.Equal(t, domain.RoleEditor, claims.Role)
+	assert.True(t, claims.Allows(domain.PermissionWritePosts))
+	assert.False(t, claims.Allows(domain.PermissionPublishPosts))
+
+	// Test case: Used a moment ago, so last use is not written again
+	justUsed := time.Now()
+	recent := *stored
+	recent.LastUsedAt = &justUsed
+	mockRepo.On("FindByHash", hashToken(token)).Return(&recent, nil).Once()
+	mockUsers.On("FindByID", uint(1)).Return(user, nil).Once()
+
+	_, err = usecase.Execute(token)
+	assert.NoError(t, err)
+
+	// Test case: Expired
+	expired := *stored
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	mockRepo.On("FindByHash", hashToken(token)).Return(&expired, nil).Once()
+	_, err = usecase.Execute(token)
+	assert.Equal(t, ErrInvalidAPIToken, err)
+
+	// Test case: Unknown or revoked token
+	mockRepo.On("FindByHash", hashToken(token)).Return(nil, nil).Once()
+	_, err = usecase.Execute(token)
+	assert.Equal(t, ErrInvalidAPIToken, err)
+
+	// Test case: Not an API token at all
+	_, err = usecase.Execute("eyJhbGciOiJIUzI1NiJ9.e30.sig")
+	assert.Equal(t, ErrInvalidAPIToken, err)
+
+	// Test case: Unverified email under the reject policy
+	mockRepo.On("FindByHash", hashToken(token)).Return(stored, nil).Once()
+	mockUsers.On("FindByID", uint(1)).Return(&domain.User{ID: 1, Role: domain.RoleEditor}, nil).Once()
+	_, err = usecase.Execute(token)
+	assert.Equal(t, domain.ErrEmailNotVerified, err)
+
+	mockRepo.AssertExpectations(t)
+	mockUsers.AssertExpectations(t)
+}
@@ -17,6 +17,11 @@ func (uc *GetBlogPostsUseCase) Execute(publishedOnly bool) ([]domain.Blog, error
 	return uc.BlogRepository.FindAll(publishedOnly)
 }
 
+// ExecutePage retrieves a single page of posts, newest first.
+func (uc *GetBlogPostsUseCase) ExecutePage(publishedOnly bool, req domain.PageRequest) (*domain.BlogPage, error) {
+	return paginate(uc.BlogRepository, domain.BlogFilter{PublishedOnly: publishedOnly}, req)
+}
+
 // GetBlogPostsByCategoryUseCase retrieves published blog posts by category slug.
 type GetBlogPostsByCategoryUseCase struct {
 	BlogRepository     domain.BlogRepository
@@ -37,9 +42,28 @@ func (uc *GetBlogPostsByCategoryUseCase) Execute(categorySlug string, publishedO
 	return uc.BlogRepository.FindByCategoryID(category.ID, publishedOnly)
 }
 
+// ExecutePage retrieves a single page of posts in the category, newest first,
+// together with the category itself.
+func (uc *GetBlogPostsByCategoryUseCase) ExecutePage(categorySlug string, publishedOnly bool, req domain.PageRequest) (*domain.Category, *domain.BlogPage, error) {
+	category, err := uc.CategoryRepository.FindBySlug(categorySlug)
+	if err != nil {
+		return nil, nil, err
+	}
+	if category == nil {
+		return nil, nil, domain.ErrNotFound
+	}
+
+	page, err := paginate(uc.BlogRepository, domain.BlogFilter{PublishedOnly: publishedOnly, CategoryID: category.ID}, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return category, page, nil
+}
+
 // GetBlogPostBySlugUseCase retrieves a single blog post by its slug.
 type GetBlogPostBySlugUseCase struct {
-	BlogRepository domain.BlogRepository
+	BlogRepository  domain.BlogRepository
+	ContentRenderer domain.ContentRenderer
 }
 
 func (uc *GetBlogPostBySlugUseCase) Execute(postSlug string) (*domain.Blog, error) {
@@ -50,6 +74,29 @@ func (uc *GetBlogPostBySlugUseCase) Execute(postSlug string) (*domain.Blog, erro
 		}
 		return nil, err
 	}
+	if post == nil {
+		return nil, domain.ErrNotFound
+	}
+	ensureContentRendered(uc.BlogRepository, uc.ContentRenderer, post)
+	return post, nil
+}
+
+// GetBlogPostByIDUseCase retrieves a single blog post by its ID.
+type GetBlogPostByIDUseCase struct {
+	BlogRepository domain.BlogRepository
+}
+
+func (uc *GetBlogPostByIDUseCase) Execute(id uint) (*domain.Blog, error) {
+	post, err := uc.BlogRepository.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if post == nil {
+		return nil, domain.ErrNotFound
+	}
 	return post, nil
 }
 
@@ -57,36 +104,85 @@ func (uc *GetBlogPostBySlugUseCase) Execute(postSlug string) (*domain.Blog, erro
 type CreateBlogPostUseCase struct {
 	BlogRepository     domain.BlogRepository
 	CategoryRepository domain.CategoryRepository
+	TagRepository      domain.TagRepository
+	ContentRenderer    domain.ContentRenderer
 }
 
 type CreateBlogPostRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Slug        string `json:"slug" binding:"required"`
-	Content     string `json:"content"`
-	Photo       string `json:"photo"`
-	IsPublished bool   `json:"is_published"`
-	CategoryID  uint   `json:"category_id" binding:"required"`
+	Title         string   `json:"title" form:"title" binding:"required"`
+	Slug          string   `json:"slug" form:"slug" binding:"required"`
+	Content       string   `json:"content" form:"content"`
+	ContentFormat string   `json:"content_format" form:"content_format"` // Defaults to Markdown
+	Photo         string   `json:"photo" form:"photo"`
+	IsPublished   bool     `json:"is_published" form:"is_published"`
+	CategoryID    uint     `json:"category_id" form:"category_id" binding:"required"`
+	Tags          []string `json:"tags" form:"tags"` // Tag names; missing tags are created
 }
 
-func (uc *CreateBlogPostUseCase) Execute(req CreateBlogPostRequest) (*domain.Blog, error) {
+// Execute creates the post on behalf of the actor, who becomes its author.
+func (uc *CreateBlogPostUseCase) Execute(req CreateBlogPostRequest, actor Actor) (*domain.Blog, error) {
+	if actor.UserID == 0 {
+		return nil, domain.ErrUnauthorized
+	}
+	if !actor.Can(domain.PermissionWritePosts) {
+		return nil, domain.ErrForbidden
+	}
+	// Authors may only save drafts; publishing is reserved for editors
+	if req.IsPublished && !actor.Can(domain.PermissionPublishPosts) {
+		return nil, domain.ErrForbidden
+	}
+
 	// Check if category exists
-	_, err := uc.CategoryRepository.FindByID(req.CategoryID)
+	category, err := uc.CategoryRepository.FindByID(req.CategoryID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrNotFound // Category not found
 		}
 		return nil, err // Other error
 	}
+	if category == nil {
+		return nil, domain.ErrNotFound // Category not found
+	}
+
+	// Slugs are unique; the repository catches a post created concurrently
+	existing, err := uc.BlogRepository.FindBySlug(req.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, domain.ErrAlreadyExists
+	}
+
+	format := req.ContentFormat
+	if format == "" {
+		format = domain.ContentFormatMarkdown
+	}
+	if !domain.IsValidContentFormat(format) {
+		return nil, domain.ErrInvalidInput
+	}
+	contentHTML, err := uc.ContentRenderer.Render(req.Content, format)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := resolveTags(uc.TagRepository, req.Tags)
+	if err != nil {
+		return nil, err
+	}
 
 	blog := &domain.Blog{
-		Title:       req.Title,
-		Slug:        req.Slug,
-		Content:     req.Content,
-		Photo:       req.Photo,
-		TimeCreated: time.Now(),
-		TimeUpdate:  time.Now(),
-		IsPublished: req.IsPublished,
-		CategoryID:  req.CategoryID,
+		Title:              req.Title,
+		Slug:               req.Slug,
+		Content:            req.Content,
+		ContentFormat:      format,
+		ContentHTML:        contentHTML,
+		ContentHTMLVersion: uc.ContentRenderer.Version(),
+		Photo:              req.Photo,
+		TimeCreated:        time.Now(),
+		TimeUpdate:         time.Now(),
+		IsPublished:        req.IsPublished,
+		CategoryID:         req.CategoryID,
+		AuthorID:           &actor.UserID,
+		Tags:               tags,
 	}
 
 	err = uc.BlogRepository.Create(blog)
@@ -95,3 +191,126 @@ func (uc *CreateBlogPostUseCase) Execute(req CreateBlogPostRequest) (*domain.Blo
 	}
 	return blog, nil
 }
+
+// UpdateBlogPostUseCase handles partial updates of an existing blog post.
+type UpdateBlogPostUseCase struct {
+	BlogRepository     domain.BlogRepository
+	CategoryRepository domain.CategoryRepository
+	TagRepository      domain.TagRepository
+	ContentRenderer    domain.ContentRenderer
+}
+
+// UpdateBlogPostRequest carries the fields to change. Nil fields are left untouched.
+type UpdateBlogPostRequest struct {
+	Title         *string   `json:"title"`
+	Slug          *string   `json:"slug"`
+	Content       *string   `json:"content"`
+	ContentFormat *string   `json:"content_format"`
+	Photo         *string   `json:"photo"`
+	IsPublished   *bool     `json:"is_published"`
+	CategoryID    *uint     `json:"category_id"`
+	Tags          *[]string `json:"tags"` // Replaces all tags; an empty list removes them
+}
+
+func (uc *UpdateBlogPostUseCase) Execute(id uint, req UpdateBlogPostRequest, actor Actor) (*domain.Blog, error) {
+	blog, err := uc.BlogRepository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if blog == nil {
+		return nil, domain.ErrNotFound
+	}
+	if !actor.canModifyPost(blog) {
+		return nil, domain.ErrForbidden
+	}
+	if req.IsPublished != nil && *req.IsPublished != blog.IsPublished && !actor.Can(domain.PermissionPublishPosts) {
+		return nil, domain.ErrForbidden
+	}
+
+	if req.Title != nil {
+		if *req.Title == "" {
+			return nil, domain.ErrInvalidInput
+		}
+		blog.Title = *req.Title
+	}
+	if req.Slug != nil && *req.Slug != blog.Slug {
+		if *req.Slug == "" {
+			return nil, domain.ErrInvalidInput
+		}
+		// Slugs are unique, so make sure no other post already uses the new one
+		existing, err := uc.BlogRepository.FindBySlug(*req.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != blog.ID {
+			return nil, domain.ErrAlreadyExists
+		}
+		blog.Slug = *req.Slug
+	}
+	if req.ContentFormat != nil {
+		if !domain.IsValidContentFormat(*req.ContentFormat) {
+			return nil, domain.ErrInvalidInput
+		}
+		blog.ContentFormat = *req.ContentFormat
+	}
+	if req.Content != nil {
+		blog.Content = *req.Content
+	}
+	if req.Content != nil || req.ContentFormat != nil {
+		contentHTML, err := uc.ContentRenderer.Render(blog.Content, blog.ContentFormat)
+		if err != nil {
+			return nil, err
+		}
+		blog.ContentHTML = contentHTML
+		blog.ContentHTMLVersion = uc.ContentRenderer.Version()
+	}
+	if req.Photo != nil {
+		blog.Photo = *req.Photo
+	}
+	if req.IsPublished != nil {
+		blog.IsPublished = *req.IsPublished
+	}
+	if req.CategoryID != nil && *req.CategoryID != blog.CategoryID {
+		category, err := uc.CategoryRepository.FindByID(*req.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, domain.ErrNotFound // Category not found
+		}
+		blog.CategoryID = category.ID
+		blog.Category = category
+	}
+	if req.Tags != nil {
+		tags, err := resolveTags(uc.TagRepository, *req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		blog.Tags = tags
+	}
+	blog.TimeUpdate = time.Now()
+
+	if err := uc.BlogRepository.Update(blog); err != nil {
+		return nil, err
+	}
+	return blog, nil
+}
+
+// DeleteBlogPostUseCase handles the removal of a blog post.
+type DeleteBlogPostUseCase struct {
+	BlogRepository domain.BlogRepository
+}
+
+func (uc *DeleteBlogPostUseCase) Execute(id uint, actor Actor) error {
+	blog, err := uc.BlogRepository.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if blog == nil {
+		return domain.ErrNotFound
+	}
+	if !actor.canModifyPost(blog) {
+		return domain.ErrForbidden
+	}
+	return uc.BlogRepository.Delete(id)
+}
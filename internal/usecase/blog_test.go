@@ -4,6 +4,7 @@ import (
 	"errors"
 	"programming_blog_go/internal/domain"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,12 +22,20 @@ func (m *MockBlogRepository) Create(blog *domain.Blog) error {
 
 func (m *MockBlogRepository) FindByID(id uint) (*domain.Blog, error) {
 	args := m.Called(id)
-	return args.Get(0).(*domain.Blog), args.Error(1)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.Blog), args.Error(1)
 }
 
 func (m *MockBlogRepository) FindBySlug(slug string) (*domain.Blog, error) {
 	args := m.Called(slug)
-	return args.Get(0).(*domain.Blog), args.Error(1)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.Blog), args.Error(1)
 }
 
 func (m *MockBlogRepository) FindAll(publishedOnly bool) ([]domain.Blog, error) {
@@ -39,11 +48,46 @@ func (m *MockBlogRepository) FindByCategoryID(categoryID uint, publishedOnly boo
 	return args.Get(0).([]domain.Blog), args.Error(1)
 }
 
+func (m *MockBlogRepository) CountByCategoryID(categoryID uint) (int64, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBlogRepository) Count(filter domain.BlogFilter) (int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBlogRepository) FindPage(filter domain.BlogFilter, offset, limit int) ([]domain.Blog, error) {
+	args := m.Called(filter, offset, limit)
+	return args.Get(0).([]domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) FindPageByCursor(filter domain.BlogFilter, cursor domain.Cursor, limit int) ([]domain.Blog, error) {
+	args := m.Called(filter, cursor, limit)
+	return args.Get(0).([]domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) Search(query string, page domain.PageRequest) ([]domain.SearchResult, int64, error) {
+	args := m.Called(query, page)
+	return args.Get(0).([]domain.SearchResult), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockBlogRepository) FindSitemapPage(offset, limit int) ([]domain.Blog, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]domain.Blog), args.Error(1)
+}
+
 func (m *MockBlogRepository) Update(blog *domain.Blog) error {
 	args := m.Called(blog)
 	return args.Error(0)
 }
 
+func (m *MockBlogRepository) UpdateContentHTML(id uint, contentHTML string, version int) error {
+	args := m.Called(id, contentHTML, version)
+	return args.Error(0)
+}
+
 func (m *MockBlogRepository) Delete(id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -92,6 +136,23 @@ func (m *MockCategoryRepository) Delete(id uint) error {
 	return args.Error(0)
 }
 
+// MockContentRenderer is a mock implementation of domain.ContentRenderer
+type MockContentRenderer struct {
+	mock.Mock
+}
+
+func (m *MockContentRenderer) Render(content, format string) (string, error) {
+	args := m.Called(content, format)
+	return args.String(0), args.Error(1)
+}
+
+// Version is fixed; tests change a post's cached version instead.
+func (m *MockContentRenderer) Version() int {
+	return testRendererVersion
+}
+
+const testRendererVersion = 2
+
 func TestGetBlogPostsUseCase_Execute(t *testing.T) {
 	mockRepo := new(MockBlogRepository)
 	usecase := &GetBlogPostsUseCase{BlogRepository: mockRepo}
@@ -111,6 +172,59 @@ func TestGetBlogPostsUseCase_Execute(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestGetBlogPostsUseCase_ExecutePage(t *testing.T) {
+	mockRepo := new(MockBlogRepository)
+	usecase := &GetBlogPostsUseCase{BlogRepository: mockRepo}
+
+	filter := domain.BlogFilter{PublishedOnly: true}
+	now := time.Now()
+	posts := []domain.Blog{
+		{ID: 5, TimeCreated: now},
+		{ID: 4, TimeCreated: now.Add(-time.Hour)},
+		{ID: 3, TimeCreated: now.Add(-2 * time.Hour)},
+	}
+
+	// Test case: Offset page in the middle of the listing
+	mockRepo.On("Count", filter).Return(int64(7), nil).Once()
+	mockRepo.On("FindPage", filter, 2, 2).Return(posts[:2], nil).Once()
+
+	page, err := usecase.ExecutePage(true, domain.PageRequest{Page: 2, PerPage: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 2)
+	assert.Equal(t, 4, page.TotalPages)
+	assert.Equal(t, 1, page.PrevPage)
+	assert.Equal(t, 3, page.NextPage)
+
+	// Test case: Cursor page with more posts after it
+	cursor := domain.Cursor{TimeCreated: now.Add(time.Hour), ID: 6}
+	mockRepo.On("Count", filter).Return(int64(7), nil).Once()
+	mockRepo.On("FindPageByCursor", filter, mock.AnythingOfType("domain.Cursor"), 3).Return(posts, nil).Once()
+
+	page, err = usecase.ExecutePage(true, domain.PageRequest{PerPage: 2, Cursor: cursor.Encode()})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Blog{posts[0], posts[1]}, page.Posts)
+	assert.Zero(t, page.Page)
+
+	next, err := domain.DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), next.ID)
+	assert.False(t, next.Before)
+
+	prev, err := domain.DecodeCursor(page.PrevCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), prev.ID)
+	assert.True(t, prev.Before)
+
+	// Test case: Malformed cursor
+	mockRepo.On("Count", filter).Return(int64(7), nil).Once()
+
+	page, err = usecase.ExecutePage(true, domain.PageRequest{Cursor: "not-a-cursor"})
+	assert.Equal(t, domain.ErrInvalidInput, err)
+	assert.Nil(t, page)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestGetBlogPostsByCategoryUseCase_Execute(t *testing.T) {
 	mockBlogRepo := new(MockBlogRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
@@ -185,15 +299,84 @@ func TestGetBlogPostBySlugUseCase_Execute(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestGetBlogPostBySlugUseCase_Execute_RendersLegacyContent(t *testing.T) {
+	mockRepo := new(MockBlogRepository)
+	mockRenderer := new(MockContentRenderer)
+	usecase := &GetBlogPostBySlugUseCase{BlogRepository: mockRepo, ContentRenderer: mockRenderer}
+
+	legacy := &domain.Blog{ID: 3, Slug: "old-post", Content: "Hello", ContentFormat: domain.ContentFormatPlain}
+	mockRepo.On("FindBySlug", "old-post").Return(legacy, nil).Once()
+	mockRenderer.On("Render", "Hello", domain.ContentFormatPlain).Return("<p>Hello</p>", nil).Once()
+	mockRepo.On("UpdateContentHTML", uint(3), "<p>Hello</p>", testRendererVersion).Return(nil).Once()
+
+	blog, err := usecase.Execute("old-post")
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>Hello</p>", blog.ContentHTML)
+
+	// Test case: HTML cached by an older renderer is rendered again
+	stale := &domain.Blog{ID: 4, Slug: "stale", Content: "```go\nx\n```", ContentFormat: domain.ContentFormatMarkdown,
+		ContentHTML: "<pre><code>x</code></pre>", ContentHTMLVersion: testRendererVersion - 1}
+	mockRepo.On("FindBySlug", "stale").Return(stale, nil).Once()
+	mockRenderer.On("Render", stale.Content, domain.ContentFormatMarkdown).Return(`<pre class="chroma">x</pre>`, nil).Once()
+	mockRepo.On("UpdateContentHTML", uint(4), `<pre class="chroma">x</pre>`, testRendererVersion).Return(nil).Once()
+
+	blog, err = usecase.Execute("stale")
+	assert.NoError(t, err)
+	assert.Equal(t, `<pre class="chroma">x</pre>`, blog.ContentHTML)
+
+	// Test case: Current HTML is served from the cache
+	current := &domain.Blog{ID: 5, Slug: "current", Content: "x", ContentHTML: "<p>x</p>", ContentHTMLVersion: testRendererVersion}
+	mockRepo.On("FindBySlug", "current").Return(current, nil).Once()
+
+	_, err = usecase.Execute("current")
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockRenderer.AssertExpectations(t)
+}
+
+func TestRerenderPostsUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockBlogRepository)
+	mockRenderer := new(MockContentRenderer)
+	usecase := &RerenderPostsUseCase{BlogRepository: mockRepo, ContentRenderer: mockRenderer}
+
+	mockRepo.On("FindAll", false).Return([]domain.Blog{
+		{ID: 1, Content: "a", ContentFormat: domain.ContentFormatPlain, ContentHTML: "<p>a</p>", ContentHTMLVersion: testRendererVersion},
+		{ID: 2, Content: "b", ContentFormat: domain.ContentFormatPlain, ContentHTML: "<p>b</p>"},
+		{ID: 3, Content: "c", ContentFormat: domain.ContentFormatPlain},
+	}, nil).Once()
+	mockRenderer.On("Render", "b", domain.ContentFormatPlain).Return("<p>b!</p>", nil).Once()
+	mockRenderer.On("Render", "c", domain.ContentFormatPlain).Return("<p>c!</p>", nil).Once()
+	mockRepo.On("UpdateContentHTML", uint(2), "<p>b!</p>", testRendererVersion).Return(nil).Once()
+	mockRepo.On("UpdateContentHTML", uint(3), "<p>c!</p>", testRendererVersion).Return(nil).Once()
+
+	rendered, err := usecase.Execute(Actor{Role: domain.RoleAdmin})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rendered)
+
+	// Test case: Authors cannot
+	_, err = usecase.Execute(Actor{UserID: 2, Role: domain.RoleAuthor})
+	assert.Equal(t, domain.ErrForbidden, err)
+
+	mockRepo.AssertExpectations(t)
+	mockRenderer.AssertExpectations(t)
+}
+
 func TestCreateBlogPostUseCase_Execute(t *testing.T) {
 	mockBlogRepo := new(MockBlogRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
+	mockRenderer := new(MockContentRenderer)
 	usecase := &CreateBlogPostUseCase{
 		BlogRepository:     mockBlogRepo,
 		CategoryRepository: mockCategoryRepo,
+		ContentRenderer:    mockRenderer,
 	}
+	mockRenderer.On("Render", "Some content", domain.ContentFormatMarkdown).Return("<p>Some content</p>", nil)
+	mockBlogRepo.On("FindBySlug", "new-post").Return(nil, nil)
 
 	categoryID := uint(1)
+	authorID := uint(42)
+	editor := Actor{UserID: authorID, Role: domain.RoleEditor}
 	existingCategory := &domain.Category{ID: categoryID, Name: "Go Lang"}
 
 	request := CreateBlogPostRequest{
@@ -209,12 +392,15 @@ func TestCreateBlogPostUseCase_Execute(t *testing.T) {
 	mockCategoryRepo.On("FindByID", categoryID).Return(existingCategory, nil).Once()
 	mockBlogRepo.On("Create", mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
 
-	blog, err := usecase.Execute(request)
+	blog, err := usecase.Execute(request, editor)
 	assert.NoError(t, err)
 	assert.NotNil(t, blog)
 	assert.Equal(t, request.Title, blog.Title)
 	assert.Equal(t, request.Slug, blog.Slug)
 	assert.Equal(t, request.CategoryID, blog.CategoryID)
+	assert.Equal(t, authorID, *blog.AuthorID)
+	assert.Equal(t, domain.ContentFormatMarkdown, blog.ContentFormat)
+	assert.Equal(t, "<p>Some content</p>", blog.ContentHTML)
 	mockBlogRepo.AssertExpectations(t)
 	mockCategoryRepo.AssertExpectations(t)
 
@@ -222,7 +408,7 @@ func TestCreateBlogPostUseCase_Execute(t *testing.T) {
 	mockCategoryRepo.On("FindByID", uint(999)).Return(nil, domain.ErrNotFound).Once()
 	request.CategoryID = uint(999)
 
-	blog, err = usecase.Execute(request)
+	blog, err = usecase.Execute(request, editor)
 	assert.Equal(t, domain.ErrNotFound, err)
 	assert.Nil(t, blog)
 	mockCategoryRepo.AssertExpectations(t)
@@ -232,9 +418,183 @@ func TestCreateBlogPostUseCase_Execute(t *testing.T) {
 	mockBlogRepo.On("Create", mock.AnythingOfType("*domain.Blog")).Return(errors.New("db error")).Once()
 	request.CategoryID = categoryID // Reset for this test
 
-	blog, err = usecase.Execute(request)
+	blog, err = usecase.Execute(request, editor)
 	assert.Error(t, err)
 	assert.Nil(t, blog)
 	mockBlogRepo.AssertExpectations(t)
 	mockCategoryRepo.AssertExpectations(t)
+
+	// Test case: Slug already taken
+	mockCategoryRepo.On("FindByID", categoryID).Return(existingCategory, nil).Once()
+	mockBlogRepo.On("FindBySlug", "taken").Return(&domain.Blog{ID: 7, Slug: "taken"}, nil).Once()
+	taken := request
+	taken.Slug = "taken"
+
+	blog, err = usecase.Execute(taken, editor)
+	assert.Equal(t, domain.ErrAlreadyExists, err)
+	assert.Nil(t, blog)
+	mockBlogRepo.AssertNotCalled(t, "Create", mock.MatchedBy(func(b *domain.Blog) bool { return b.Slug == "taken" }))
+
+	// Test case: Missing author
+	blog, err = usecase.Execute(request, Actor{Role: domain.RoleEditor})
+	assert.Equal(t, domain.ErrUnauthorized, err)
+	assert.Nil(t, blog)
+
+	// Test case: Readers cannot write posts
+	blog, err = usecase.Execute(request, Actor{UserID: authorID, Role: domain.RoleReader})
+	assert.Equal(t, domain.ErrForbidden, err)
+	assert.Nil(t, blog)
+
+	// Test case: Authors cannot publish directly
+	blog, err = usecase.Execute(request, Actor{UserID: authorID, Role: domain.RoleAuthor})
+	assert.Equal(t, domain.ErrForbidden, err)
+	assert.Nil(t, blog)
+}
+
+func TestGetBlogPostByIDUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockBlogRepository)
+	usecase := &GetBlogPostByIDUseCase{BlogRepository: mockRepo}
+
+	expectedBlog := &domain.Blog{ID: 1, Title: "Test Post"}
+
+	// Test case: Post found
+	mockRepo.On("FindByID", uint(1)).Return(expectedBlog, nil).Once()
+
+	blog, err := usecase.Execute(1)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedBlog, blog)
+
+	// Test case: Post not found (repository returns nil, nil)
+	mockRepo.On("FindByID", uint(2)).Return(nil, nil).Once()
+
+	blog, err = usecase.Execute(2)
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Nil(t, blog)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateBlogPostUseCase_Execute(t *testing.T) {
+	mockBlogRepo := new(MockBlogRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockRenderer := new(MockContentRenderer)
+	usecase := &UpdateBlogPostUseCase{
+		BlogRepository:     mockBlogRepo,
+		CategoryRepository: mockCategoryRepo,
+		ContentRenderer:    mockRenderer,
+	}
+
+	newTitle := "Fixed Title"
+	newSlug := "fixed-title"
+	newCategoryID := uint(2)
+	authorID := uint(42)
+	author := Actor{UserID: authorID, Role: domain.RoleAuthor}
+	published := true
+
+	// Test case: Partial update only touches the given fields
+	existing := &domain.Blog{ID: 1, Title: "Tpyo", Slug: "old-slug", Content: "Body", CategoryID: 1, AuthorID: &authorID}
+	mockBlogRepo.On("FindByID", uint(1)).Return(existing, nil).Once()
+	mockBlogRepo.On("Update", mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+
+	blog, err := usecase.Execute(1, UpdateBlogPostRequest{Title: &newTitle}, author)
+	assert.NoError(t, err)
+	assert.Equal(t, newTitle, blog.Title)
+	assert.Equal(t, "old-slug", blog.Slug)
+	assert.Equal(t, "Body", blog.Content)
+	assert.False(t, blog.TimeUpdate.IsZero())
+
+	// Test case: Slug already taken by another post
+	existing = &domain.Blog{ID: 1, Slug: "old-slug", CategoryID: 1, AuthorID: &authorID}
+	mockBlogRepo.On("FindByID", uint(1)).Return(existing, nil).Once()
+	mockBlogRepo.On("FindBySlug", newSlug).Return(&domain.Blog{ID: 7, Slug: newSlug}, nil).Once()
+
+	blog, err = usecase.Execute(1, UpdateBlogPostRequest{Slug: &newSlug}, author)
+	assert.Equal(t, domain.ErrAlreadyExists, err)
+	assert.Nil(t, blog)
+
+	// Test case: Category change to a missing category
+	existing = &domain.Blog{ID: 1, Slug: "old-slug", CategoryID: 1, AuthorID: &authorID}
+	mockBlogRepo.On("FindByID", uint(1)).Return(existing, nil).Once()
+	mockCategoryRepo.On("FindByID", newCategoryID).Return(nil, nil).Once()
+
+	blog, err = usecase.Execute(1, UpdateBlogPostRequest{CategoryID: &newCategoryID}, author)
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Nil(t, blog)
+
+	// Test case: Authors cannot edit someone else's post
+	otherAuthorID := uint(7)
+	existing = &domain.Blog{ID: 2, Slug: "other", CategoryID: 1, AuthorID: &otherAuthorID}
+	mockBlogRepo.On("FindByID", uint(2)).Return(existing, nil).Once()
+
+	blog, err = usecase.Execute(2, UpdateBlogPostRequest{Title: &newTitle}, author)
+	assert.Equal(t, domain.ErrForbidden, err)
+	assert.Nil(t, blog)
+
+	// Test case: Authors cannot publish their own drafts
+	existing = &domain.Blog{ID: 1, Slug: "old-slug", CategoryID: 1, AuthorID: &authorID}
+	mockBlogRepo.On("FindByID", uint(1)).Return(existing, nil).Once()
+
+	blog, err = usecase.Execute(1, UpdateBlogPostRequest{IsPublished: &published}, author)
+	assert.Equal(t, domain.ErrForbidden, err)
+	assert.Nil(t, blog)
+
+	// Test case: Editors can edit and publish any post
+	existing = &domain.Blog{ID: 2, Slug: "other", CategoryID: 1, AuthorID: &otherAuthorID}
+	mockBlogRepo.On("FindByID", uint(2)).Return(existing, nil).Once()
+	mockBlogRepo.On("Update", mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+
+	blog, err = usecase.Execute(2, UpdateBlogPostRequest{IsPublished: &published}, Actor{UserID: 1, Role: domain.RoleEditor})
+	assert.NoError(t, err)
+	assert.True(t, blog.IsPublished)
+
+	// Test case: Changing the format re-renders the cached HTML
+	markdown := domain.ContentFormatMarkdown
+	existing = &domain.Blog{ID: 1, Slug: "old-slug", Content: "*hi*", ContentFormat: domain.ContentFormatPlain, CategoryID: 1, AuthorID: &authorID}
+	mockBlogRepo.On("FindByID", uint(1)).Return(existing, nil).Once()
+	mockRenderer.On("Render", "*hi*", domain.ContentFormatMarkdown).Return("<p><em>hi</em></p>", nil).Once()
+	mockBlogRepo.On("Update", mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+
+	blog, err = usecase.Execute(1, UpdateBlogPostRequest{ContentFormat: &markdown}, author)
+	assert.NoError(t, err)
+	assert.Equal(t, "<p><em>hi</em></p>", blog.ContentHTML)
+
+	// Test case: Post not found
+	mockBlogRepo.On("FindByID", uint(99)).Return(nil, nil).Once()
+
+	blog, err = usecase.Execute(99, UpdateBlogPostRequest{Title: &newTitle}, author)
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Nil(t, blog)
+
+	mockBlogRepo.AssertExpectations(t)
+	mockCategoryRepo.AssertExpectations(t)
+	mockRenderer.AssertExpectations(t)
+}
+
+func TestDeleteBlogPostUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockBlogRepository)
+	usecase := &DeleteBlogPostUseCase{BlogRepository: mockRepo}
+
+	authorID := uint(42)
+	author := Actor{UserID: authorID, Role: domain.RoleAuthor}
+
+	// Test case: Authors can delete their own post
+	mockRepo.On("FindByID", uint(1)).Return(&domain.Blog{ID: 1, AuthorID: &authorID}, nil).Once()
+	mockRepo.On("Delete", uint(1)).Return(nil).Once()
+
+	err := usecase.Execute(1, author)
+	assert.NoError(t, err)
+
+	// Test case: Authors cannot delete posts they did not write
+	mockRepo.On("FindByID", uint(3)).Return(&domain.Blog{ID: 3}, nil).Once()
+
+	err = usecase.Execute(3, author)
+	assert.Equal(t, domain.ErrForbidden, err)
+
+	// Test case: Post not found
+	mockRepo.On("FindByID", uint(2)).Return(nil, nil).Once()
+
+	err = usecase.Execute(2, author)
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	mockRepo.AssertExpectations(t)
 }
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"programming_blog_go/internal/domain"
+	"strings"
+	"time"
 )
 
 // GetAllCategoriesUseCase retrieves all categories.
@@ -13,3 +15,129 @@ type GetAllCategoriesUseCase struct {
 func (uc *GetAllCategoriesUseCase) Execute() ([]domain.Category, error) {
 	return uc.CategoryRepository.FindAll()
 }
+
+// CreateCategoryUseCase handles the creation of a new category.
+type CreateCategoryUseCase struct {
+	CategoryRepository domain.CategoryRepository
+}
+
+type CreateCategoryRequest struct {
+	Name string `json:"name" form:"name" binding:"required"`
+	Slug string `json:"slug" form:"slug"` // Derived from the name when empty
+}
+
+func (uc *CreateCategoryUseCase) Execute(req CreateCategoryRequest, actor Actor) (*domain.Category, error) {
+	if !actor.Can(domain.PermissionManageCategories) {
+		return nil, domain.ErrForbidden
+	}
+	name := strings.TrimSpace(req.Name)
+	slug := req.Slug
+	if slug == "" {
+		slug = slugify(name)
+	}
+	if name == "" || !isValidSlug(slug) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	existing, err := uc.CategoryRepository.FindBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, domain.ErrAlreadyExists
+	}
+
+	category := &domain.Category{
+		Name:      name,
+		Slug:      slug,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := uc.CategoryRepository.Create(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategoryUseCase renames a category or changes its slug.
+type UpdateCategoryUseCase struct {
+	CategoryRepository domain.CategoryRepository
+}
+
+// UpdateCategoryRequest carries the fields to change. Nil fields are left untouched.
+type UpdateCategoryRequest struct {
+	Name *string `json:"name"`
+	Slug *string `json:"slug"`
+}
+
+func (uc *UpdateCategoryUseCase) Execute(id uint, req UpdateCategoryRequest, actor Actor) (*domain.Category, error) {
+	if !actor.Can(domain.PermissionManageCategories) {
+		return nil, domain.ErrForbidden
+	}
+
+	category, err := uc.CategoryRepository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, domain.ErrInvalidInput
+		}
+		category.Name = name
+	}
+	if req.Slug != nil && *req.Slug != category.Slug {
+		if !isValidSlug(*req.Slug) {
+			return nil, domain.ErrInvalidInput
+		}
+		existing, err := uc.CategoryRepository.FindBySlug(*req.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != category.ID {
+			return nil, domain.ErrAlreadyExists
+		}
+		category.Slug = *req.Slug
+	}
+	category.UpdatedAt = time.Now()
+
+	if err := uc.CategoryRepository.Update(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategoryUseCase removes a category that no longer has any posts.
+type DeleteCategoryUseCase struct {
+	CategoryRepository domain.CategoryRepository
+	BlogRepository     domain.BlogRepository
+}
+
+func (uc *DeleteCategoryUseCase) Execute(id uint, actor Actor) error {
+	if !actor.Can(domain.PermissionManageCategories) {
+		return domain.ErrForbidden
+	}
+
+	category, err := uc.CategoryRepository.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return domain.ErrNotFound
+	}
+
+	// Posts reference categories with ON DELETE RESTRICT; report that up front
+	// instead of surfacing a foreign key violation.
+	count, err := uc.BlogRepository.CountByCategoryID(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrCategoryInUse
+	}
+	return uc.CategoryRepository.Delete(id)
+}
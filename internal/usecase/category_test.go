@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateCategoryUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockCategoryRepository)
+	usecase := &CreateCategoryUseCase{CategoryRepository: mockRepo}
+
+	admin := Actor{UserID: 1, Role: domain.RoleAdmin}
+	request := CreateCategoryRequest{Name: "Go", Slug: "go"}
+
+	// Test case: Successful creation
+	mockRepo.On("FindBySlug", "go").Return(nil, nil).Once()
+	mockRepo.On("Create", mock.AnythingOfType("*domain.Category")).Return(nil).Once()
+
+	category, err := usecase.Execute(request, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, "Go", category.Name)
+	assert.Equal(t, "go", category.Slug)
+
+	// Test case: Slug already taken
+	mockRepo.On("FindBySlug", "go").Return(&domain.Category{ID: 3, Slug: "go"}, nil).Once()
+
+	category, err = usecase.Execute(request, admin)
+	assert.Equal(t, domain.ErrAlreadyExists, err)
+	assert.Nil(t, category)
+
+	// Test case: Slug derived from the name
+	mockRepo.On("FindBySlug", "web-development").Return(nil, nil).Once()
+	mockRepo.On("Create", mock.AnythingOfType("*domain.Category")).Return(nil).Once()
+
+	category, err = usecase.Execute(CreateCategoryRequest{Name: "Web Development"}, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, "web-development", category.Slug)
+
+	// Test case: Malformed slug
+	category, err = usecase.Execute(CreateCategoryRequest{Name: "Go", Slug: "Go Lang"}, admin)
+	assert.Equal(t, domain.ErrInvalidInput, err)
+	assert.Nil(t, category)
+
+	// Test case: Editors cannot manage categories
+	category, err = usecase.Execute(request, Actor{UserID: 2, Role: domain.RoleEditor})
+	assert.Equal(t, domain.ErrForbidden, err)
+	assert.Nil(t, category)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateCategoryUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockCategoryRepository)
+	usecase := &UpdateCategoryUseCase{CategoryRepository: mockRepo}
+
+	admin := Actor{UserID: 1, Role: domain.RoleAdmin}
+	newName := "Golang"
+	newSlug := "golang"
+
+	// Test case: Rename keeps the slug
+	mockRepo.On("FindByID", uint(1)).Return(&domain.Category{ID: 1, Name: "Go", Slug: "go"}, nil).Once()
+	mockRepo.On("Update", mock.AnythingOfType("*domain.Category")).Return(nil).Once()
+
+	category, err := usecase.Execute(1, UpdateCategoryRequest{Name: &newName}, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, "Golang", category.Name)
+	assert.Equal(t, "go", category.Slug)
+
+	// Test case: New slug belongs to another category
+	mockRepo.On("FindByID", uint(1)).Return(&domain.Category{ID: 1, Name: "Go", Slug: "go"}, nil).Once()
+	mockRepo.On("FindBySlug", "golang").Return(&domain.Category{ID: 2, Slug: "golang"}, nil).Once()
+
+	category, err = usecase.Execute(1, UpdateCategoryRequest{Slug: &newSlug}, admin)
+	assert.Equal(t, domain.ErrAlreadyExists, err)
+	assert.Nil(t, category)
+
+	// Test case: Category not found
+	mockRepo.On("FindByID", uint(9)).Return(nil, nil).Once()
+
+	category, err = usecase.Execute(9, UpdateCategoryRequest{Name: &newName}, admin)
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Nil(t, category)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteCategoryUseCase_Execute(t *testing.T) {
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockBlogRepo := new(MockBlogRepository)
+	usecase := &DeleteCategoryUseCase{CategoryRepository: mockCategoryRepo, BlogRepository: mockBlogRepo}
+
+	admin := Actor{UserID: 1, Role: domain.RoleAdmin}
+
+	// Test case: Empty category is deleted
+	mockCategoryRepo.On("FindByID", uint(1)).Return(&domain.Category{ID: 1}, nil).Once()
+	mockBlogRepo.On("CountByCategoryID", uint(1)).Return(int64(0), nil).Once()
+	mockCategoryRepo.On("Delete", uint(1)).Return(nil).Once()
+
+	err := usecase.Execute(1, admin)
+	assert.NoError(t, err)
+
+	// Test case: Category still has posts
+	mockCategoryRepo.On("FindByID", uint(2)).Return(&domain.Category{ID: 2}, nil).Once()
+	mockBlogRepo.On("CountByCategoryID", uint(2)).Return(int64(3), nil).Once()
+
+	err = usecase.Execute(2, admin)
+	assert.Equal(t, domain.ErrCategoryInUse, err)
+
+	// Test case: Category not found
+	mockCategoryRepo.On("FindByID", uint(9)).Return(nil, nil).Once()
+
+	err = usecase.Execute(9, admin)
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	mockCategoryRepo.AssertExpectations(t)
+	mockBlogRepo.AssertExpectations(t)
+}
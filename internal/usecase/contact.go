@@ -10,9 +10,9 @@ type SendContactMessageUseCase struct {
 }
 
 type SendContactMessageRequest struct {
-	Name    string `json:"name" binding:"required"`
-	Email   string `json:"email" binding:"required,email"`
-	Content string `json:"content" binding:"required"`
+	Name    string `json:"name" form:"name" binding:"required"`
+	Email   string `json:"email" form:"email" binding:"required,email"`
+	Content string `json:"content" form:"content" binding:"required"`
 }
 
 func (uc *SendContactMessageUseCase) Execute(req SendContactMessageRequest) error {
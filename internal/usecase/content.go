@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"log"
+
+	"programming_blog_go/internal/domain"
+)
+
+// ensureContentRendered renders the cached HTML of posts saved before it was
+// stored, e.g. plain-text posts from before Markdown support, or by an older
+// version of the renderer, and persists it so the next view is served from the
+// cache. Failures only cost the cache: the template falls back to the escaped
+// source, or keeps the older HTML. It reports whether new HTML was stored.
+func ensureContentRendered(repo domain.BlogRepository, renderer domain.ContentRenderer, post *domain.Blog) bool {
+	if post.Content == "" || (post.ContentHTML != "" && post.ContentHTMLVersion == renderer.Version()) {
+		return false
+	}
+	contentHTML, err := renderer.Render(post.Content, post.ContentFormat)
+	if err != nil {
+		log.Printf("Error rendering content of post %d: %v", post.ID, err)
+		return false
+	}
+	post.ContentHTML = contentHTML
+	post.ContentHTMLVersion = renderer.Version()
+	if err := repo.UpdateContentHTML(post.ID, contentHTML, post.ContentHTMLVersion); err != nil {
+		log.Printf("Error caching rendered content of post %d: %v", post.ID, err)
+		return false
+	}
+	return true
+}
+
+// RerenderPostsUseCase renders the cached HTML of every post that is missing or
+// stale, so feeds, which serve the cache as is, catch up after a renderer change
+// without waiting for each post to be viewed.
+type RerenderPostsUseCase struct {
+	BlogRepository  domain.BlogRepository
+	ContentRenderer domain.ContentRenderer
+}
+
+// Execute returns how many posts got new HTML.
+func (uc *RerenderPostsUseCase) Execute(actor Actor) (int, error) {
+	if !actor.Can(domain.PermissionEditAnyPost) {
+		return 0, domain.ErrForbidden
+	}
+	posts, err := uc.BlogRepository.FindAll(false)
+	if err != nil {
+		return 0, err
+	}
+	rendered := 0
+	for i := range posts {
+		if ensureContentRendered(uc.BlogRepository, uc.ContentRenderer, &posts[i]) {
+			rendered++
+		}
+	}
+	return rendered, nil
+}
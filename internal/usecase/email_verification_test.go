@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMailerService is a mock implementation of domain.MailerService
+type MockMailerService struct {
+	mock.Mock
+}
+
+func (m *MockMailerService) SendEmail(to []string, subject, body string) error {
+	args := m.Called(to, subject, body)
+	return args.Error(0)
+}
+
+func TestEmailVerification_Token(t *testing.T) {
+	v := &EmailVerification{Secret: []byte("secret"), TTL: time.Hour}
+	user := &domain.User{ID: 7, Email: "a:b@example.com"}
+	now := time.Now()
+
+	token := v.Token(user, now)
+	userID, email, err := v.Parse(token, now)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), userID)
+	assert.Equal(t, "a:b@example.com", email)
+
+	// Expired
+	_, _, err = v.Parse(token, now.Add(2*time.Hour))
+	assert.Equal(t, ErrInvalidVerificationToken, err)
+
+	// Signed with another secret
+	other := &EmailVerification{Secret: []byte("other"), TTL: time.Hour}
+	_, _, err = other.Parse(token, now)
+	assert.Equal(t, ErrInvalidVerificationToken, err)
+
+	// Payload swapped for another user's
+	forged := strings.SplitN(v.Token(&domain.User{ID: 8, Email: "x@example.com"}, now), ".", 2)[0] +
+		"." + strings.SplitN(token, ".", 2)[1]
+	_, _, err = v.Parse(forged, now)
+	assert.Equal(t, ErrInvalidVerificationToken, err)
+
+	_, _, err = v.Parse("garbage", now)
+	assert.Equal(t, ErrInvalidVerificationToken, err)
+}
+
+func TestRegisterUserUseCase_Execute_SendsVerificationEmail(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockMailer := new(MockMailerService)
+	verification := &EmailVerification{Secret: []byte("secret"), TTL: time.Hour, BaseURL: "https://blog.example.com", Mailer: mockMailer}
+	usecase := &RegisterUserUseCase{UserRepository: mockRepo, PasswordHasher: testPasswordHasher, EmailVerification: verification}
+
+	mockRepo.On("FindByUsername", "alice").Return(nil, nil).Once()
+	mockRepo.On("FindByEmail", "alice@example.com").Return(nil, nil).Once()
+	mockRepo.On("Create", mock.AnythingOfType("*domain.User")).Return(nil).Once()
+	mockMailer.On("SendEmail", []string{"alice@example.com"}, mock.Anything, mock.MatchedBy(func(body string) bool {
+		return strings.Contains(body, "https://blog.example.com/verify-email?token=")
+	})).Return(nil).Once()
+
+	user, err := usecase.Execute(RegisterUserRequest{Username: "alice", Email: "alice@example.com", Password: "password"})
+	assert.NoError(t, err)
+	assert.False(t, user.IsEmailVerified())
+	assert.NotNil(t, user.VerificationSentAt) // Starts the resend cooldown
+
+	mockRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestVerifyEmailUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	verification := &EmailVerification{Secret: []byte("secret"), TTL: time.Hour}
+	usecase := &VerifyEmailUseCase{UserRepository: mockRepo, EmailVerification: verification}
+
+	token := verification.Token(&domain.User{ID: 2, Email: "bob@example.com"}, time.Now())
+
+	// Test case: Address is confirmed
+	mockRepo.On("FindByID", uint(2)).Return(&domain.User{ID: 2, Email: "bob@example.com"}, nil).Once()
+	mockRepo.On("Update", mock.AnythingOfType("*domain.User")).Return(nil).Once()
+
+	user, err := usecase.Execute(token)
+	assert.NoError(t, err)
+	assert.True(t, user.IsEmailVerified())
+
+	// Test case: Address changed after the link was sent
+	mockRepo.On("FindByID", uint(2)).Return(&domain.User{ID: 2, Email: "new@example.com"}, nil).Once()
+
+	user, err = usecase.Execute(token)
+	assert.Equal(t, ErrInvalidVerificationToken, err)
+	assert.Nil(t, user)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResendVerificationEmailUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockMailer := new(MockMailerService)
+	verification := &EmailVerification{Secret: []byte("secret"), TTL: time.Hour, Mailer: mockMailer}
+	usecase := &ResendVerificationEmailUseCase{UserRepository: mockRepo, EmailVerification: verification, Cooldown: 5 * time.Minute}
+	req := ResendVerificationEmailRequest{Email: "carol@example.com"}
+
+	// Test case: Link is sent
+	mockRepo.On("FindByEmail", "carol@example.com").Return(&domain.User{ID: 3, Email: "carol@example.com"}, nil).Once()
+	mockRepo.On("ClaimVerificationEmail", uint(3), mock.AnythingOfType("time.Time"), 5*time.Minute).Return(true, nil).Once()
+	mockMailer.On("SendEmail", []string{"carol@example.com"}, mock.Anything, mock.Anything).Return(nil).Once()
+
+	assert.NoError(t, usecase.Execute(req))
+
+	// Test case: Within the cooldown nothing is sent, but the response is the same
+	mockRepo.On("FindByEmail", "carol@example.com").Return(&domain.User{ID: 3, Email: "carol@example.com"}, nil).Once()
+	mockRepo.On("ClaimVerificationEmail", uint(3), mock.AnythingOfType("time.Time"), 5*time.Minute).Return(false, nil).Once()
+
+	assert.NoError(t, usecase.Execute(req))
+
+	mockRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestAuthenticateUserUseCase_Execute_UnverifiedPolicy(t *testing.T) {
+	hash, _ := testPasswordHasher.Hash("password")
+	unverified := func() *domain.User {
+		return &domain.User{ID: 3, Username: "carol", Password: hash, Role: domain.RoleEditor}
+	}
+	req := AuthenticateUserRequest{Username: "carol", Password: "password"}
+
+	tests := []struct {
+		policy   UnverifiedLoginPolicy
+		wantRole domain.Role
+		wantErr  error
+	}{
+		{UnverifiedLoginAllow, domain.RoleEditor, nil},
+		{UnverifiedLoginLimit, domain.RoleReader, nil},
+		{UnverifiedLoginReject, "", domain.ErrEmailNotVerified},
+		{"", "", domain.ErrEmailNotVerified},
+	}
+	for _, tt := range tests {
+		mockRepo := new(MockUserRepository)
+		mockRepo.On("FindByUsername", "carol").Return(unverified(), nil).Once()
+		usecase := &AuthenticateUserUseCase{UserRepository: mockRepo, PasswordHasher: testPasswordHasher, UnverifiedPolicy: tt.policy}
+
+		user, err := usecase.Execute(req)
+		assert.Equal(t, tt.wantErr, err, "policy %q", tt.policy)
+		if tt.wantErr == nil {
+			assert.Equal(t, tt.wantRole, user.Role, "policy %q", tt.policy)
+		}
+	}
+
+	// Verified accounts keep their role under any policy
+	verifiedAt := time.Now()
+	verified := unverified()
+	verified.EmailVerifiedAt = &verifiedAt
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("FindByUsername", "carol").Return(verified, nil).Once()
+	usecase := &AuthenticateUserUseCase{UserRepository: mockRepo, PasswordHasher: testPasswordHasher, UnverifiedPolicy: UnverifiedLoginReject}
+
+	user, err := usecase.Execute(req)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleEditor, user.Role)
+}
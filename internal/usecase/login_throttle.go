@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"programming_blog_go/internal/domain"
+)
+
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+
+// LoginThrottle slows down password guessing. Failed logins are counted per account
+// and per client address; past a number of free failures each further one locks
+// the key for twice as long as the one before, up to MaxLockout. Accounts are keyed
+// by the username as typed, so unknown usernames lock just like real ones and the
+// lockout reveals nothing about which accounts exist.
+type LoginThrottle struct {
+	Attempts            domain.LoginAttemptRepository
+	FreeAccountFailures int           // Failures per account before the first lockout
+	FreeIPFailures      int           // Failures per client address before the first lockout
+	BaseLockout         time.Duration // Lockout after the first failure past the free ones
+	MaxLockout          time.Duration
+	ResetAfter          time.Duration // Failures older than this are forgotten
+}
+
+func accountThrottleKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// keys returns the keys a login attempt counts against; without a known client
+// address, e.g. from the command line, only the account is throttled.
+func (t *LoginThrottle) keys(username, ip string) []throttleKey {
+	keys := []throttleKey{{accountThrottleKey(username), t.FreeAccountFailures}}
+	if ip != "" {
+		keys = append(keys, throttleKey{ipThrottleKey(ip), t.FreeIPFailures})
+	}
+	return keys
+}
+
+type throttleKey struct {
+	key  string
+	free int
+}
+
+// LoginAttempt is a login attempt counted against its keys before the credentials
+// are checked. A wrong password or code needs nothing more: the attempt already
+// counts as a failure. A nil attempt, from a nil throttle, does nothing.
+type LoginAttempt struct {
+	throttle *LoginThrottle
+	username string
+	keys     []reservedKey
+}
+
+type reservedKey struct {
+	key    string
+	locked bool // This attempt started a lockout
+}
+
+// Attempt counts a login attempt against the account and the address and returns
+// ErrTooManyLoginAttempts while either is locked. The lock is checked and the
+// attempt counted in one step, so concurrent attempts cannot all get past a lock
+// before the first of them fails. A nil throttle allows everything.
+func (t *LoginThrottle) Attempt(username, ip string, now time.Time) (*LoginAttempt, error) {
+	if t == nil {
+		return nil, nil
+	}
+	attempt := &LoginAttempt{throttle: t, username: username}
+	for _, k := range t.keys(username, ip) {
+		lockout := func(count int) time.Duration { return t.lockout(count, k.free) }
+		allowed, locked, err := t.Attempts.ReserveAttempt(k.key, now, t.ResetAfter, lockout)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			// The keys counted so far were not really tried
+			if err := attempt.Release(); err != nil {
+				return nil, err
+			}
+			return nil, ErrTooManyLoginAttempts
+		}
+		attempt.keys = append(attempt.keys, reservedKey{k.key, locked})
+	}
+	return attempt, nil
+}
+
+// Release takes the attempt back, for credentials that turned out to be right or
+// were never checked. Earlier failures are kept.
+func (a *LoginAttempt) Release() error {
+	if a == nil {
+		return nil
+	}
+	for _, k := range a.keys {
+		if err := a.throttle.Attempts.ReleaseAttempt(k.key, k.locked); err != nil {
+			return err
+		}
+	}
+	a.keys = nil
+	return nil
+}
+
+// Succeeded takes the attempt back and forgets the account's failures. The address
+// keeps its count, so one working account does not reset guessing at the others.
+func (a *LoginAttempt) Succeeded() error {
+	if a == nil {
+		return nil
+	}
+	if err := a.Release(); err != nil {
+		return err
+	}
+	return a.throttle.Attempts.Reset(accountThrottleKey(a.username))
+}
+
+// lockout returns how long to lock a key after its count-th failure.
+func (t *LoginThrottle) lockout(count, free int) time.Duration {
+	over := count - free
+	if over <= 0 {
+		return 0
+	}
+	lockout := t.BaseLockout
+	for i := 1; i < over && lockout < t.MaxLockout; i++ {
+		lockout *= 2
+	}
+	return min(lockout, t.MaxLockout)
+}
+
+// UnlockLoginUseCase lets an administrator lift a lockout early, for a user's
+// account or for a client address.
+type UnlockLoginUseCase struct {
+	UserRepository domain.UserRepository
+	Throttle       *LoginThrottle
+}
+
+type UnlockLoginRequest struct {
+	UserID uint   // Unlocks the user's account
+	IP     string // Unlocks a client address
+}
+
+func (uc *UnlockLoginUseCase) Execute(req UnlockLoginRequest, actor Actor) error {
+	if !actor.Can(domain.PermissionManageUsers) {
+		return domain.ErrForbidden
+	}
+	if (req.UserID == 0) == (req.IP == "") {
+		return domain.ErrInvalidInput
+	}
+	if req.IP != "" {
+		return uc.Throttle.Attempts.Reset(ipThrottleKey(req.IP))
+	}
+
+	user, err := uc.UserRepository.FindByID(req.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return uc.Throttle.Attempts.Reset(accountThrottleKey(user.Username))
+}
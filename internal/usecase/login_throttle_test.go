@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLoginAttempts keeps failed logins in a map, counting them like the
+// PostgreSQL repository.
+type memoryLoginAttempts map[string]*domain.LoginFailures
+
+func (m memoryLoginAttempts) ReserveAttempt(key string, at time.Time, resetAfter time.Duration, lockout func(count int) time.Duration) (bool, bool, error) {
+	for k, failures := range m {
+		if failures.LastFailedAt.Before(at.Add(-resetAfter)) && (failures.LockedUntil == nil || !at.Before(*failures.LockedUntil)) {
+			delete(m, k)
+		}
+	}
+	failures := m[key]
+	if failures == nil {
+		failures = &domain.LoginFailures{Key: key, LastFailedAt: at}
+		m[key] = failures
+	}
+	if failures.LockedUntil != nil && at.Before(*failures.LockedUntil) {
+		return false, false, nil
+	}
+	if failures.LastFailedAt.Before(at.Add(-resetAfter)) {
+		failures.Failures = 0
+	}
+	failures.Failures++
+	failures.LastFailedAt = at
+	if d := lockout(failures.Failures); d > 0 {
+		until := at.Add(d)
+		failures.LockedUntil = &until
+		return true, true, nil
+	}
+	return true, false, nil
+}
+
+func (m memoryLoginAttempts) ReleaseAttempt(key string, unlock bool) error {
+	if failures := m[key]; failures != nil {
+		failures.Failures = max(failures.Failures-1, 0)
+		if unlock {
+			failures.LockedUntil = nil
+		}
+	}
+	return nil
+}
+
+func (m memoryLoginAttempts) Reset(key string) error {
+	delete(m, key)
+	return nil
+}
+
+// expireLocks pretends the lockouts are over.
+func (m memoryLoginAttempts) expireLocks() {
+	past := time.Now().Add(-time.Second)
+	for _, failures := range m {
+		if failures.LockedUntil != nil {
+			failures.LockedUntil = &past
+		}
+	}
+}
+
+func newTestLoginThrottle() (*LoginThrottle, memoryLoginAttempts) {
+	attempts := memoryLoginAttempts{}
+	return &LoginThrottle{
+		Attempts:            attempts,
+		FreeAccountFailures: 2,
+		FreeIPFailures:      4,
+		BaseLockout:         time.Minute,
+		MaxLockout:          10 * time.Minute,
+		ResetAfter:          time.Hour,
+	}, attempts
+}
+
+func TestLoginThrottle_Lockout(t *testing.T) {
+	throttle, _ := newTestLoginThrottle()
+	tests := []struct {
+		count int
+		want  time.Duration
+	}{
+		{1, 0},
+		{2, 0},
+		{3, time.Minute},
+		{4, 2 * time.Minute},
+		{5, 4 * time.Minute},
+		{6, 8 * time.Minute},
+		{7, 10 * time.Minute},
+		{1000, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, throttle.lockout(tt.count, 2), "failure %d", tt.count)
+	}
+}
+
+func TestLoginThrottle_Attempt(t *testing.T) {
+	throttle, attempts := newTestLoginThrottle()
+	now := time.Now()
+
+	// Attempts in flight count before any of them fails, so a burst stops at the lock
+	var inFlight []*LoginAttempt
+	for range 3 {
+		attempt, err := throttle.Attempt("alice", "198.51.100.1", now)
+		require.NoError(t, err)
+		inFlight = append(inFlight, attempt)
+	}
+	_, err := throttle.Attempt("alice", "198.51.100.2", now)
+	assert.Equal(t, ErrTooManyLoginAttempts, err)
+	assert.Equal(t, 3, attempts[ipThrottleKey("198.51.100.1")].Failures)
+	assert.NotContains(t, attempts, ipThrottleKey("198.51.100.2"), "refused attempts are not counted")
+
+	// The attempt that started the lockout turns out to be right: the lock is lifted
+	require.NoError(t, inFlight[2].Release())
+	assert.Nil(t, attempts[accountThrottleKey("alice")].LockedUntil)
+	assert.Equal(t, 2, attempts[accountThrottleKey("alice")].Failures)
+	require.NoError(t, inFlight[1].Succeeded())
+	assert.NotContains(t, attempts, accountThrottleKey("alice"))
+	assert.Equal(t, 1, attempts[ipThrottleKey("198.51.100.1")].Failures)
+}
+
+func TestLoginThrottle_Attempt_ForgetsStaleKeys(t *testing.T) {
+	throttle, attempts := newTestLoginThrottle()
+	now := time.Now()
+	lockedUntil := now.Add(time.Minute)
+	attempts["stale"] = &domain.LoginFailures{Key: "stale", Failures: 2, LastFailedAt: now.Add(-2 * time.Hour)}
+	attempts["locked"] = &domain.LoginFailures{Key: "locked", Failures: 9, LastFailedAt: now.Add(-2 * time.Hour), LockedUntil: &lockedUntil}
+	attempts["recent"] = &domain.LoginFailures{Key: "recent", Failures: 1, LastFailedAt: now.Add(-time.Minute)}
+
+	// Failures past ResetAfter would count from zero anyway, so their rows go
+	_, err := throttle.Attempt("alice", "198.51.100.1", now)
+	require.NoError(t, err)
+	assert.NotContains(t, attempts, "stale")
+	assert.Contains(t, attempts, "locked", "a running lockout is kept")
+	assert.Contains(t, attempts, "recent")
+}
+
+func TestAuthenticateUserUseCase_Execute_Throttled(t *testing.T) {
+	hash, _ := testPasswordHasher.Hash("password")
+	verifiedAt := time.Now()
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("FindByUsername", "alice").Return(&domain.User{ID: 1, Username: "alice", Password: hash, EmailVerifiedAt: &verifiedAt}, nil)
+	mockRepo.On("FindByUsername", "nobody").Return(nil, nil)
+	mockRepo.On("FindByUsername", "Nobody").Return(nil, nil)
+	throttle, attempts := newTestLoginThrottle()
+	usecase := &AuthenticateUserUseCase{UserRepository: mockRepo, PasswordHasher: testPasswordHasher, UnverifiedPolicy: UnverifiedLoginReject, Throttle: throttle}
+	login := func(username, password, ip string) error {
+		_, err := usecase.Execute(AuthenticateUserRequest{Username: username, Password: password, ClientIP: ip})
+		return err
+	}
+
+	// Unknown users and wrong passwords look the same
+	assert.Equal(t, ErrInvalidCredentials, login("nobody", "password", "198.51.100.1"))
+	assert.Equal(t, ErrInvalidCredentials, login("alice", "wrong", "198.51.100.1"))
+
+	// Past the free failures the account is locked, even for the right password
+	assert.Equal(t, ErrInvalidCredentials, login("alice", "wrong", "198.51.100.2"))
+	assert.Equal(t, ErrInvalidCredentials, login("alice", "wrong", "198.51.100.3"))
+	assert.Equal(t, ErrTooManyLoginAttempts, login("alice", "password", "198.51.100.4"))
+
+	// Unknown usernames lock just the same
+	for range 3 {
+		login("Nobody", "wrong", "198.51.100.5")
+	}
+	assert.Equal(t, ErrTooManyLoginAttempts, login("nobody", "password", "198.51.100.6"))
+
+	// Once the lockout is over, a successful login forgets the failures
+	attempts.expireLocks()
+	assert.NoError(t, login("alice", "password", "198.51.100.4"))
+	assert.NotContains(t, attempts, accountThrottleKey("alice"))
+
+	// One address guessing at many accounts is locked too
+	for _, username := range []string{"a", "b", "c", "d", "e"} {
+		mockRepo.On("FindByUsername", username).Return(nil, nil)
+		login(username, "wrong", "203.0.113.9")
+	}
+	assert.Equal(t, ErrTooManyLoginAttempts, login("alice", "password", "203.0.113.9"))
+	assert.NoError(t, login("alice", "password", "198.51.100.4"))
+}
+
+func TestUnlockLoginUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	throttle, attempts := newTestLoginThrottle()
+	usecase := &UnlockLoginUseCase{UserRepository: mockRepo, Throttle: throttle}
+	admin := Actor{UserID: 1, Role: domain.RoleAdmin}
+	now := time.Now()
+	for range 3 {
+		_, err := throttle.Attempt("Alice", "203.0.113.9", now)
+		require.NoError(t, err)
+	}
+	_, err := throttle.Attempt("alice", "", now)
+	require.Equal(t, ErrTooManyLoginAttempts, err)
+
+	// Test case: Only admins unlock
+	err = usecase.Execute(UnlockLoginRequest{UserID: 2}, Actor{UserID: 2, Role: domain.RoleEditor})
+	assert.Equal(t, domain.ErrForbidden, err)
+
+	// Test case: Account
+	mockRepo.On("FindByID", uint(2)).Return(&domain.User{ID: 2, Username: "alice"}, nil).Once()
+	assert.NoError(t, usecase.Execute(UnlockLoginRequest{UserID: 2}, admin))
+	attempt, err := throttle.Attempt("alice", "", now)
+	assert.NoError(t, err)
+	require.NoError(t, attempt.Succeeded())
+	assert.Contains(t, attempts, ipThrottleKey("203.0.113.9"), "the address keeps its failures")
+
+	// Test case: Address
+	assert.NoError(t, usecase.Execute(UnlockLoginRequest{IP: "203.0.113.9"}, admin))
+	assert.Empty(t, attempts)
+
+	// Test case: Exactly one of user and address
+	assert.Equal(t, domain.ErrInvalidInput, usecase.Execute(UnlockLoginRequest{}, admin))
+
+	// Test case: Unknown user
+	mockRepo.On("FindByID", uint(9)).Return(nil, nil).Once()
+	assert.Equal(t, ErrUserNotFound, usecase.Execute(UnlockLoginRequest{UserID: 9}, admin))
+
+	mockRepo.AssertExpectations(t)
+}
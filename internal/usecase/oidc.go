@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"programming_blog_go/internal/domain"
+)
+
+var (
+	ErrUnknownIdentityProvider  = errors.New("unknown identity provider")
+	ErrInvalidExternalLogin     = errors.New("invalid or expired external login")
+	ErrExternalLoginFailed      = errors.New("login with the identity provider failed")
+	ErrExternalEmailNotVerified = errors.New("the identity provider has not verified the email address")
+	ErrExternalSignupDisabled   = errors.New("no account with this email address")
+	// A local account with the address exists but never proved it owns it; linking
+	// would hand the account to whoever registered it
+	ErrExternalLinkNotAllowed = errors.New("an account with this email address exists but is not verified")
+)
+
+// OIDCLoginState seals what the callback of an external login needs to know —
+// the state, nonce and PKCE verifier sent with the authorization request — into
+// a signed, expiring value the handler keeps in a cookie. Like the 2FA challenge
+// it needs no storage on the server.
+type OIDCLoginState struct {
+	Secret []byte
+	TTL    time.Duration
+}
+
+// pendingExternalLogin is an external login started but not yet completed.
+type pendingExternalLogin struct {
+	Provider string `json:"p"`
+	State    string `json:"s"`
+	Nonce    string `json:"n"`
+	Verifier string `json:"v"`
+	Next     string `json:"r"` // Where to go after logging in
+	Expires  int64  `json:"e"`
+}
+
+func (s *OIDCLoginState) seal(login pendingExternalLogin) (string, error) {
+	payload, err := json.Marshal(login)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+func (s *OIDCLoginState) open(sealed string, now time.Time) (*pendingExternalLogin, error) {
+	encoded, signature, ok := strings.Cut(sealed, ".")
+	if !ok {
+		return nil, ErrInvalidExternalLogin
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return nil, ErrInvalidExternalLogin
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidExternalLogin
+	}
+	var login pendingExternalLogin
+	if err := json.Unmarshal(payload, &login); err != nil || now.Unix() > login.Expires {
+		return nil, ErrInvalidExternalLogin
+	}
+	return &login, nil
+}
+
+func (s *OIDCLoginState) sign(encoded string) []byte {
+	key := hmac.New(sha256.New, s.Secret)
+	key.Write([]byte("oidc-login-state"))
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// findIdentityProvider returns the configured provider with the given ID.
+func findIdentityProvider(providers []domain.IdentityProvider, id string) (domain.IdentityProvider, error) {
+	for _, provider := range providers {
+		if provider.ID() == id {
+			return provider, nil
+		}
+	}
+	return nil, ErrUnknownIdentityProvider
+}
+
+// randomString returns n random bytes in URL-safe base64.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// pkceChallenge derives the S256 code challenge from a code verifier (RFC 7636).
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// StartExternalLoginUseCase begins a login at an identity provider.
+type StartExternalLoginUseCase struct {
+	Providers []domain.IdentityProvider
+	States    *OIDCLoginState
+}
+
+// ExternalLoginRedirect tells the handler where to send the browser and what to
+// remember until it comes back.
+type ExternalLoginRedirect struct {
+	URL      string
+	State    string // Sealed pending login for the callback
+	StateTTL time.Duration
+}
+
+func (uc *StartExternalLoginUseCase) Execute(providerID, next string) (*ExternalLoginRedirect, error) {
+	provider, err := findIdentityProvider(uc.Providers, providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	login := pendingExternalLogin{
+		Provider: provider.ID(),
+		Next:     next,
+		Expires:  time.Now().Add(uc.States.TTL).Unix(),
+	}
+	if login.State, err = randomString(32); err != nil {
+		return nil, err
+	}
+	if login.Nonce, err = randomString(32); err != nil {
+		return nil, err
+	}
+	if login.Verifier, err = randomString(32); err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(login.State, login.Nonce, pkceChallenge(login.Verifier))
+	if err != nil {
+		log.Printf("Error starting login with %s: %v", provider.ID(), err)
+		return nil, ErrExternalLoginFailed
+	}
+	sealed, err := uc.States.seal(login)
+	if err != nil {
+		return nil, err
+	}
+	return &ExternalLoginRedirect{URL: authURL, State: sealed, StateTTL: uc.States.TTL}, nil
+}
+
+// CompleteExternalLoginUseCase finishes a login when the provider redirects back.
+// A known identity logs in its linked user. A new one is linked to the existing
+// user with the same email address if both the provider and the blog have verified
+// that address, or else becomes a new reader account when sign-up is allowed.
+type CompleteExternalLoginUseCase struct {
+	Providers              []domain.IdentityProvider
+	States                 *OIDCLoginState
+	UserRepository         domain.UserRepository
+	UserIdentityRepository domain.UserIdentityRepository
+	AllowSignup            bool
+	UnverifiedPolicy       UnverifiedLoginPolicy // Same policy as at login
+}
+
+// CompleteExternalLoginRequest is the query of the provider's redirect back.
+type CompleteExternalLoginRequest struct {
+	Code  string `form:"code"`
+	State string `form:"state"`
+	Error string `form:"error"` // Set instead of code when the login was refused or cancelled
+}
+
+// Execute returns the user to log in and where to send them afterwards. sealedState
+// is the value StartExternalLoginUseCase returned for this browser.
+func (uc *CompleteExternalLoginUseCase) Execute(providerID string, req CompleteExternalLoginRequest, sealedState string) (*domain.User, string, error) {
+	provider, err := findIdentityProvider(uc.Providers, providerID)
+	if err != nil {
+		return nil, "", err
+	}
+	login, err := uc.States.open(sealedState, time.Now())
+	if err != nil {
+		return nil, "", err
+	}
+	// The state ties the callback to the browser that started the login
+	if login.Provider != provider.ID() || subtle.ConstantTimeCompare([]byte(login.State), []byte(req.State)) != 1 {
+		return nil, "", ErrInvalidExternalLogin
+	}
+	if req.Error != "" || req.Code == "" {
+		return nil, "", ErrExternalLoginFailed
+	}
+
+	identity, err := provider.Exchange(req.Code, login.Verifier)
+	if err != nil {
+		log.Printf("Error completing login with %s: %v", provider.ID(), err)
+		return nil, "", ErrExternalLoginFailed
+	}
+	// The ID token must answer this login, not be replayed from another one
+	if subtle.ConstantTimeCompare([]byte(identity.Nonce), []byte(login.Nonce)) != 1 {
+		return nil, "", ErrInvalidExternalLogin
+	}
+
+	user, err := uc.findOrLinkUser(provider.ID(), identity)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := applyUnverifiedPolicy(uc.UnverifiedPolicy, user); err != nil {
+		return nil, "", err
+	}
+	return user, login.Next, nil
+}
+
+func (uc *CompleteExternalLoginUseCase) findOrLinkUser(providerID string, identity *domain.ExternalIdentity) (*domain.User, error) {
+	linked, err := uc.UserIdentityRepository.FindBySubject(providerID, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		user, err := uc.UserRepository.FindByID(linked.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrExternalLoginFailed
+		}
+		return user, nil
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrExternalEmailNotVerified
+	}
+	user, err := uc.UserRepository.FindByEmail(identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case user != nil && !user.IsEmailVerified():
+		return nil, ErrExternalLinkNotAllowed
+	case user == nil && !uc.AllowSignup:
+		return nil, ErrExternalSignupDisabled
+	case user == nil:
+		if user, err = uc.createUser(identity); err != nil {
+			return nil, err
+		}
+	}
+
+	link := &domain.UserIdentity{
+		UserID:    user.ID,
+		Provider:  providerID,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.UserIdentityRepository.Create(link); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// createUser signs up the person as a reader without a local password; they can
+// set one later through the password reset.
+func (uc *CompleteExternalLoginUseCase) createUser(identity *domain.ExternalIdentity) (*domain.User, error) {
+	username, err := uc.availableUsername(identity)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user := &domain.User{
+		Username:        username,
+		Email:           identity.Email,
+		Role:            domain.RoleReader, // As with registration, an admin grants more
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		EmailVerifiedAt: &now, // The provider vouched for the address
+		SessionVersion:  1,
+	}
+	if err := uc.UserRepository.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+var usernameDisallowed = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// maxUsernameAttempts bounds the search for a free username before a random suffix is used.
+const maxUsernameAttempts = 20
+
+// availableUsername derives a username from the provider's preferred username or
+// the email address, numbering it when taken.
+func (uc *CompleteExternalLoginUseCase) availableUsername(identity *domain.ExternalIdentity) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = strings.Trim(usernameDisallowed.ReplaceAllString(strings.ToLower(base), "-"), "-.")
+	if base == "" {
+		base = "user"
+	}
+
+	for i := 1; i <= maxUsernameAttempts; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s%d", base, i)
+		}
+		existing, err := uc.UserRepository.FindByUsername(candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+	}
+	suffix, err := randomString(6)
+	if err != nil {
+		return "", err
+	}
+	return base + "-" + strings.ToLower(suffix), nil
+}
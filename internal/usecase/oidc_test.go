@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"errors"
+	"net/url"
+	"programming_blog_go/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockUserIdentityRepository is a mock implementation of domain.UserIdentityRepository
+type MockUserIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserIdentityRepository) FindBySubject(provider, subject string) (*domain.UserIdentity, error) {
+	args := m.Called(provider, subject)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.UserIdentity), args.Error(1)
+}
+
+func (m *MockUserIdentityRepository) Create(identity *domain.UserIdentity) error {
+	args := m.Called(identity)
+	return args.Error(0)
+}
+
+// stubIdentityProvider logs everyone in as identity, echoing the nonce of the last
+// authorization request like a real provider would.
+type stubIdentityProvider struct {
+	identity  domain.ExternalIdentity
+	nonce     string
+	challenge string
+	verifier  string // Verifier presented at the exchange
+}
+
+func (p *stubIdentityProvider) ID() string          { return "company" }
+func (p *stubIdentityProvider) DisplayName() string { return "Company SSO" }
+
+func (p *stubIdentityProvider) AuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	p.nonce, p.challenge = nonce, codeChallenge
+	return "https://idp.example.com/authorize?" + url.Values{"state": {state}}.Encode(), nil
+}
+
+func (p *stubIdentityProvider) Exchange(code, codeVerifier string) (*domain.ExternalIdentity, error) {
+	if code != "good-code" {
+		return nil, errors.New("invalid_grant")
+	}
+	p.verifier = codeVerifier
+	identity := p.identity
+	identity.Nonce = p.nonce
+	return &identity, nil
+}
+
+type externalLoginTest struct {
+	provider   *stubIdentityProvider
+	users      *MockUserRepository
+	identities *MockUserIdentityRepository
+	start      *StartExternalLoginUseCase
+	complete   *CompleteExternalLoginUseCase
+}
+
+func newExternalLoginTest() *externalLoginTest {
+	provider := &stubIdentityProvider{identity: domain.ExternalIdentity{
+		Subject:       "user-42",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+	}}
+	states := &OIDCLoginState{Secret: []byte("secret"), TTL: 10 * time.Minute}
+	providers := []domain.IdentityProvider{provider}
+	users := new(MockUserRepository)
+	identities := new(MockUserIdentityRepository)
+	return &externalLoginTest{
+		provider:   provider,
+		users:      users,
+		identities: identities,
+		start:      &StartExternalLoginUseCase{Providers: providers, States: states},
+		complete: &CompleteExternalLoginUseCase{
+			Providers:              providers,
+			States:                 states,
+			UserRepository:         users,
+			UserIdentityRepository: identities,
+			AllowSignup:            true,
+			UnverifiedPolicy:       UnverifiedLoginReject,
+		},
+	}
+}
+
+// begin starts a login and returns the callback request and the state cookie.
+func (lt *externalLoginTest) begin(t *testing.T) (CompleteExternalLoginRequest, string) {
+	redirect, err := lt.start.Execute("company", "/addpage")
+	require.NoError(t, err)
+	u, err := url.Parse(redirect.URL)
+	require.NoError(t, err)
+	return CompleteExternalLoginRequest{Code: "good-code", State: u.Query().Get("state")}, redirect.State
+}
+
+func TestExternalLogin_LinkedIdentity(t *testing.T) {
+	lt := newExternalLoginTest()
+	verifiedAt := time.Now()
+	user := &domain.User{ID: 7, Username: "alice", EmailVerifiedAt: &verifiedAt}
+	lt.identities.On("FindBySubject", "company", "user-42").Return(&domain.UserIdentity{UserID: 7}, nil).Once()
+	lt.users.On("FindByID", uint(7)).Return(user, nil).Once()
+
+	req, state := lt.begin(t)
+	got, next, err := lt.complete.Execute("company", req, state)
+	assert.NoError(t, err)
+	assert.Equal(t, user, got)
+	assert.Equal(t, "/addpage", next)
+	// The verifier kept in the state matches the challenge sent to the provider
+	assert.Equal(t, lt.provider.challenge, pkceChallenge(lt.provider.verifier))
+
+	lt.users.AssertExpectations(t)
+	lt.identities.AssertExpectations(t)
+}
+
+func TestExternalLogin_LinksByVerifiedEmail(t *testing.T) {
+	lt := newExternalLoginTest()
+	verifiedAt := time.Now()
+	user := &domain.User{ID: 7, Email: "alice@example.com", EmailVerifiedAt: &verifiedAt}
+	lt.identities.On("FindBySubject", "company", "user-42").Return(nil, nil)
+	lt.users.On("FindByEmail", "alice@example.com").Return(user, nil).Once()
+	lt.identities.On("Create", mock.MatchedBy(func(link *domain.UserIdentity) bool {
+		return link.UserID == 7 && link.Provider == "company" && link.Subject == "user-42"
+	})).Return(nil).Once()
+
+	req, state := lt.begin(t)
+	got, _, err := lt.complete.Execute("company", req, state)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), got.ID)
+
+	// Test case: The local account never confirmed the address
+	lt.users.On("FindByEmail", "alice@example.com").Return(&domain.User{ID: 8, Email: "alice@example.com"}, nil).Once()
+	req, state = lt.begin(t)
+	_, _, err = lt.complete.Execute("company", req, state)
+	assert.Equal(t, ErrExternalLinkNotAllowed, err)
+
+	// Test case: The provider did not verify the address
+	lt.provider.identity.EmailVerified = false
+	req, state = lt.begin(t)
+	_, _, err = lt.complete.Execute("company", req, state)
+	assert.Equal(t, ErrExternalEmailNotVerified, err)
+
+	lt.users.AssertExpectations(t)
+	lt.identities.AssertExpectations(t)
+}
+
+func TestExternalLogin_Signup(t *testing.T) {
+	lt := newExternalLoginTest()
+	lt.provider.identity.PreferredUsername = "Alice Smith"
+	lt.identities.On("FindBySubject", "company", "user-42").Return(nil, nil)
+	lt.users.On("FindByEmail", "alice@example.com").Return(nil, nil)
+	lt.users.On("FindByUsername", "alice-smith").Return(&domain.User{ID: 1}, nil).Once()
+	lt.users.On("FindByUsername", "alice-smith2").Return(nil, nil).Once()
+	lt.users.On("Create", mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) {
+		args.Get(0).(*domain.User).ID = 9
+	}).Return(nil).Once()
+	lt.identities.On("Create", mock.AnythingOfType("*domain.UserIdentity")).Return(nil).Once()
+
+	req, state := lt.begin(t)
+	user, _, err := lt.complete.Execute("company", req, state)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice-smith2", user.Username)
+	assert.Equal(t, domain.RoleReader, user.Role)
+	assert.True(t, user.IsEmailVerified())
+	assert.Empty(t, user.Password, "no local password")
+
+	// Test case: Sign-up disabled
+	lt.complete.AllowSignup = false
+	req, state = lt.begin(t)
+	_, _, err = lt.complete.Execute("company", req, state)
+	assert.Equal(t, ErrExternalSignupDisabled, err)
+
+	lt.users.AssertExpectations(t)
+	lt.identities.AssertExpectations(t)
+}
+
+func TestExternalLogin_RejectsForgedCallbacks(t *testing.T) {
+	lt := newExternalLoginTest()
+
+	// Test case: State from another browser's login
+	req, _ := lt.begin(t)
+	_, otherState := lt.begin(t)
+	_, _, err := lt.complete.Execute("company", req, otherState)
+	assert.Equal(t, ErrInvalidExternalLogin, err)
+
+	// Test case: No state cookie
+	req, _ = lt.begin(t)
+	_, _, err = lt.complete.Execute("company", req, "")
+	assert.Equal(t, ErrInvalidExternalLogin, err)
+
+	// Test case: Expired
+	lt.start.States = &OIDCLoginState{Secret: []byte("secret"), TTL: -time.Minute}
+	req, state := lt.begin(t)
+	lt.start.States = lt.complete.States
+	_, _, err = lt.complete.Execute("company", req, state)
+	assert.Equal(t, ErrInvalidExternalLogin, err)
+
+	// Test case: ID token from another login (nonce mismatch)
+	req, state = lt.begin(t)
+	lt.provider.nonce = "replayed"
+	_, _, err = lt.complete.Execute("company", req, state)
+	assert.Equal(t, ErrInvalidExternalLogin, err)
+
+	// Test case: The provider refused the code
+	req, state = lt.begin(t)
+	req.Code = "bad-code"
+	_, _, err = lt.complete.Execute("company", req, state)
+	assert.Equal(t, ErrExternalLoginFailed, err)
+
+	// Test case: Unknown provider
+	_, err = lt.start.Execute("github", "/")
+	assert.Equal(t, ErrUnknownIdentityProvider, err)
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+)
+
+// paginate loads one page of posts matching the filter, by cursor if the request
+// carries one and by page number otherwise.
+func paginate(repo domain.BlogRepository, filter domain.BlogFilter, req domain.PageRequest) (*domain.BlogPage, error) {
+	perPage := req.PerPage
+	if perPage <= 0 {
+		perPage = domain.DefaultPageSize
+	}
+	if perPage > domain.MaxPageSize {
+		perPage = domain.MaxPageSize
+	}
+
+	total, err := repo.Count(filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &domain.BlogPage{
+		Total:      total,
+		PerPage:    perPage,
+		TotalPages: int((total + int64(perPage) - 1) / int64(perPage)),
+	}
+
+	if req.Cursor != "" {
+		cursor, err := domain.DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		// Fetch one extra post to learn whether there is anything beyond this page
+		posts, err := repo.FindPageByCursor(filter, cursor, perPage+1)
+		if err != nil {
+			return nil, err
+		}
+		hasMore := len(posts) > perPage
+		if hasMore {
+			if cursor.Before {
+				posts = posts[1:] // The extra post is the newest one
+			} else {
+				posts = posts[:perPage]
+			}
+		}
+		page.Posts = posts
+		if len(posts) > 0 {
+			// Coming from a cursor means there is always a page on the side we came from
+			if hasMore || cursor.Before {
+				page.NextCursor = domain.CursorFor(posts[len(posts)-1], false).Encode()
+			}
+			if hasMore || !cursor.Before {
+				page.PrevCursor = domain.CursorFor(posts[0], true).Encode()
+			}
+		}
+		return page, nil
+	}
+
+	pageNum := req.Page
+	if pageNum < 1 {
+		pageNum = 1
+	}
+	posts, err := repo.FindPage(filter, (pageNum-1)*perPage, perPage)
+	if err != nil {
+		return nil, err
+	}
+	page.Posts = posts
+	page.Page = pageNum
+	if pageNum > 1 {
+		page.PrevPage = pageNum - 1
+	}
+	if pageNum < page.TotalPages {
+		page.NextPage = pageNum + 1
+	}
+	return page, nil
+}
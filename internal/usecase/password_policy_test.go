@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// listBlocklist blocks the listed passwords.
+type listBlocklist []string
+
+func (l listBlocklist) Contains(password string) bool {
+	for _, blocked := range l {
+		if strings.EqualFold(blocked, password) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := &PasswordPolicy{MinLength: 8, MaxLength: 72, Blocklist: listBlocklist{"password123"}}
+	tests := []struct {
+		name     string
+		password string
+		want     error
+	}{
+		{"long enough", "correct horse battery", nil},
+		{"too short", "abc123", ErrPasswordTooShort},
+		{"length in characters, not bytes", "пароль", ErrPasswordTooShort},
+		{"non-ASCII long enough", "пароль-пароль", nil},
+		{"too long", strings.Repeat("a", 73), ErrPasswordTooLong},
+		{"breached", "Password123", ErrPasswordTooCommon},
+		{"same as username", "Alice-Smith", ErrPasswordTooCommon},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.Check(tt.password, "alice-smith"))
+		})
+	}
+
+	// A nil policy accepts anything
+	var none *PasswordPolicy
+	assert.NoError(t, none.Check("1", "alice"))
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"programming_blog_go/internal/domain"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset link")
+
+// ForgotPasswordUseCase emails a single-use link for choosing a new password.
+type ForgotPasswordUseCase struct {
+	UserRepository          domain.UserRepository
+	PasswordResetRepository domain.PasswordResetRepository
+	MailerService           domain.MailerService
+	BaseURL                 string // Absolute site root the links point at
+	TTL                     time.Duration
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" form:"email" binding:"required,email"`
+}
+
+// Execute succeeds whether or not the address is registered, so the endpoint cannot
+// be used to find out who has an account.
+func (uc *ForgotPasswordUseCase) Execute(req ForgotPasswordRequest) error {
+	user, err := uc.UserRepository.FindByEmail(req.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	token, hash, err := generateToken()
+	if err != nil {
+		return err
+	}
+	// Only the newest link works
+	if err := uc.PasswordResetRepository.DeleteByUserID(user.ID); err != nil {
+		return err
+	}
+	err = uc.PasswordResetRepository.Create(&domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(uc.TTL),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := uc.BaseURL + "/reset-password?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hello, %s!\n\n"+
+		"Someone asked to reset the password of your account. To choose a new one, open this link:\n\n%s\n\n"+
+		"The link works once and is valid for %s. If it wasn't you, ignore this message: your password stays the same.\n",
+		user.Username, link, uc.TTL)
+	if err := uc.MailerService.SendEmail([]string{user.Email}, "Reset your password", body); err != nil {
+		log.Printf("Error sending password reset email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPasswordUseCase sets a new password using a link from ForgotPasswordUseCase.
+type ResetPasswordUseCase struct {
+	UserRepository          domain.UserRepository
+	PasswordResetRepository domain.PasswordResetRepository
+	PasswordHasher          domain.PasswordHasher
+	PasswordPolicy          *PasswordPolicy
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" form:"token" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required"`
+}
+
+// Execute checks the new password before the link is used up, so a rejected
+// password can be followed by another try with the same link.
+func (uc *ResetPasswordUseCase) Execute(req ResetPasswordRequest) error {
+	if err := uc.PasswordPolicy.Check(req.Password, ""); err != nil {
+		return err
+	}
+	reset, err := uc.PasswordResetRepository.FindByHash(hashToken(req.Token))
+	if err != nil {
+		return err
+	}
+	if reset == nil || !reset.ExpiresAt.After(time.Now()) {
+		return ErrInvalidResetToken
+	}
+	user, err := uc.UserRepository.FindByID(reset.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidResetToken
+	}
+	if err := uc.PasswordPolicy.Check(req.Password, user.Username); err != nil {
+		return err
+	}
+
+	hashedPassword, err := uc.PasswordHasher.Hash(req.Password)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	user.Password = hashedPassword
+	user.SessionVersion++ // Whoever knew the old password is logged out
+	if !user.IsEmailVerified() {
+		user.EmailVerifiedAt = &now // The link proves the user reads this mailbox
+	}
+	user.UpdatedAt = now
+	// API tokens do not carry the session version, so redeeming revokes them outright
+	redeemed, err := uc.PasswordResetRepository.Redeem(reset.ID, user, now)
+	if err != nil {
+		return err
+	}
+	if !redeemed {
+		return ErrInvalidResetToken // Used by a concurrent request
+	}
+	return nil
+}
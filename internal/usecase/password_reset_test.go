@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPasswordResetRepository is a mock implementation of domain.PasswordResetRepository
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) Create(token *domain.PasswordResetToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) FindByHash(tokenHash string) (*domain.PasswordResetToken, error) {
+	args := m.Called(tokenHash)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) Redeem(tokenID uint, user *domain.User, now time.Time) (bool, error) {
+	args := m.Called(tokenID, user, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) DeleteByUserID(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func TestForgotPasswordUseCase_Execute(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+	mockMailer := new(MockMailerService)
+	usecase := &ForgotPasswordUseCase{
+		UserRepository:          mockUserRepo,
+		PasswordResetRepository: mockResetRepo,
+		MailerService:           mockMailer,
+		BaseURL:                 "https://blog.example.com",
+		TTL:                     time.Hour,
+	}
+
+	// Test case: Link is emailed and only its hash is stored
+	var stored *domain.PasswordResetToken
+	var body string
+	mockUserRepo.On("FindByEmail", "alice@example.com").Return(&domain.User{ID: 1, Email: "alice@example.com"}, nil).Once()
+	mockResetRepo.On("DeleteByUserID", uint(1)).Return(nil).Once()
+	mockResetRepo.On("Create", mock.AnythingOfType("*domain.PasswordResetToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*domain.PasswordResetToken)
+	}).Return(nil).Once()
+	mockMailer.On("SendEmail", []string{"alice@example.com"}, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		body = args.String(2)
+	}).Return(nil).Once()
+
+	err := usecase.Execute(ForgotPasswordRequest{Email: "alice@example.com"})
+	assert.NoError(t, err)
+	match := regexp.MustCompile(`https://blog\.example\.com/reset-password\?token=([\w-]+)`).FindStringSubmatch(body)
+	if assert.Len(t, match, 2) {
+		assert.Equal(t, hashToken(match[1]), stored.TokenHash)
+	}
+	assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+
+	// Test case: Unknown address looks the same to the caller
+	mockUserRepo.On("FindByEmail", "nobody@example.com").Return(nil, nil).Once()
+
+	err = usecase.Execute(ForgotPasswordRequest{Email: "nobody@example.com"})
+	assert.NoError(t, err)
+
+	mockUserRepo.AssertExpectations(t)
+	mockResetRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestResetPasswordUseCase_Execute(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+	usecase := &ResetPasswordUseCase{
+		UserRepository:          mockUserRepo,
+		PasswordResetRepository: mockResetRepo,
+		PasswordHasher:          testPasswordHasher,
+		PasswordPolicy:          &PasswordPolicy{MinLength: 8},
+	}
+	valid := &domain.PasswordResetToken{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+
+	// Test case: Password changes and existing sessions and API tokens are invalidated
+	mockResetRepo.On("FindByHash", hashToken("good-token")).Return(valid, nil).Once()
+	mockUserRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, Password: "old-hash", SessionVersion: 3}, nil).Once()
+	mockResetRepo.On("Redeem", uint(5), mock.MatchedBy(func(u *domain.User) bool {
+		return u.Password == "v2$new-secret" && u.SessionVersion == 4 && u.IsEmailVerified()
+	}), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+
+	err := usecase.Execute(ResetPasswordRequest{Token: "good-token", Password: "new-secret"})
+	assert.NoError(t, err)
+
+	// Test case: Unknown token
+	mockResetRepo.On("FindByHash", hashToken("good-token")).Return(nil, nil).Once()
+
+	err = usecase.Execute(ResetPasswordRequest{Token: "good-token", Password: "new-secret"})
+	assert.Equal(t, ErrInvalidResetToken, err)
+
+	// Test case: Expired token
+	mockResetRepo.On("FindByHash", hashToken("old-token")).
+		Return(&domain.PasswordResetToken{ID: 4, UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil).Once()
+
+	err = usecase.Execute(ResetPasswordRequest{Token: "old-token", Password: "new-secret"})
+	assert.Equal(t, ErrInvalidResetToken, err)
+
+	// Test case: A concurrent request used the link first
+	mockResetRepo.On("FindByHash", hashToken("good-token")).Return(valid, nil).Once()
+	mockUserRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, SessionVersion: 4}, nil).Once()
+	mockResetRepo.On("Redeem", uint(5), mock.AnythingOfType("*domain.User"), mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+
+	err = usecase.Execute(ResetPasswordRequest{Token: "good-token", Password: "new-secret"})
+	assert.Equal(t, ErrInvalidResetToken, err)
+
+	// Test case: A password against the policy leaves the link usable
+	err = usecase.Execute(ResetPasswordRequest{Token: "good-token", Password: "short"})
+	assert.Equal(t, ErrPasswordTooShort, err)
+
+	// Test case: The username rule is checked once the user is known, before the link is used
+	mockResetRepo.On("FindByHash", hashToken("good-token")).Return(valid, nil).Once()
+	mockUserRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, Username: "alice-smith"}, nil).Once()
+
+	err = usecase.Execute(ResetPasswordRequest{Token: "good-token", Password: "Alice-Smith"})
+	assert.Equal(t, ErrPasswordTooCommon, err)
+
+	mockUserRepo.AssertExpectations(t)
+	mockResetRepo.AssertExpectations(t)
+	mockResetRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
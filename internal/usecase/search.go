@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"html"
+	"strings"
+
+	"programming_blog_go/internal/domain"
+)
+
+// maxSearchQueryLength bounds the query text handed to PostgreSQL.
+const maxSearchQueryLength = 200
+
+// SearchPostsUseCase runs a full-text search over published blog posts.
+type SearchPostsUseCase struct {
+	BlogRepository domain.BlogRepository
+}
+
+// Execute searches for the query and returns one page of ranked results with highlighted snippets.
+func (uc *SearchPostsUseCase) Execute(query string, req domain.PageRequest) (*domain.SearchPage, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || len(query) > maxSearchQueryLength {
+		return nil, domain.ErrInvalidInput
+	}
+
+	req.Cursor = "" // Search results are ranked, so only page numbers make sense
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PerPage <= 0 {
+		req.PerPage = domain.DefaultPageSize
+	}
+	if req.PerPage > domain.MaxPageSize {
+		req.PerPage = domain.MaxPageSize
+	}
+
+	results, total, err := uc.BlogRepository.Search(query, req)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].SnippetHTML = highlightSnippet(results[i].Snippet)
+	}
+
+	page := &domain.SearchPage{
+		Query:      query,
+		Results:    results,
+		Total:      total,
+		PerPage:    req.PerPage,
+		TotalPages: int((total + int64(req.PerPage) - 1) / int64(req.PerPage)),
+		Page:       req.Page,
+	}
+	if page.Page > 1 {
+		page.PrevPage = page.Page - 1
+	}
+	if page.Page < page.TotalPages {
+		page.NextPage = page.Page + 1
+	}
+	return page, nil
+}
+
+// highlightSnippet escapes a ts_headline snippet and turns the highlight markers into <mark> tags.
+// Escaping happens first, so only the markers can ever become markup.
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, domain.SearchHighlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, domain.SearchHighlightStop, "</mark>")
+}
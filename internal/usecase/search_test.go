@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchPostsUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockBlogRepository)
+	usecase := &SearchPostsUseCase{BlogRepository: mockRepo}
+
+	snippet := "use " + domain.SearchHighlightStart + "goroutines" + domain.SearchHighlightStop + " with <care>"
+	results := []domain.SearchResult{
+		{Post: domain.Blog{ID: 1, Title: "Concurrency"}, Rank: 0.9, Snippet: snippet},
+	}
+
+	// Test case: Results are highlighted and paginated
+	mockRepo.On("Search", "goroutines", domain.PageRequest{Page: 2, PerPage: 1}).Return(results, int64(3), nil).Once()
+
+	page, err := usecase.Execute("  goroutines ", domain.PageRequest{Page: 2, PerPage: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "goroutines", page.Query)
+	assert.Equal(t, "use <mark>goroutines</mark> with &lt;care&gt;", page.Results[0].SnippetHTML)
+	assert.Equal(t, 3, page.TotalPages)
+	assert.Equal(t, 1, page.PrevPage)
+	assert.Equal(t, 3, page.NextPage)
+
+	// Test case: Empty query
+	page, err = usecase.Execute("   ", domain.PageRequest{})
+	assert.Equal(t, domain.ErrInvalidInput, err)
+	assert.Nil(t, page)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"programming_blog_go/internal/domain"
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+// StartSessionUseCase issues the first token pair after a successful login.
+type StartSessionUseCase struct {
+	AccessTokens           domain.AccessTokenService
+	RefreshTokenRepository domain.RefreshTokenRepository
+	RefreshTTL             time.Duration
+}
+
+func (uc *StartSessionUseCase) Execute(user *domain.User) (*domain.TokenPair, error) {
+	family := make([]byte, 16)
+	if _, err := rand.Read(family); err != nil {
+		return nil, err
+	}
+	return issueTokenPair(uc.AccessTokens, uc.RefreshTokenRepository, uc.RefreshTTL, user, hex.EncodeToString(family))
+}
+
+// RefreshSessionUseCase trades a refresh token for a new token pair. Each refresh
+// token works once; presenting one that was already rotated means two parties hold
+// it, so the whole family is revoked and both have to log in again.
+//
+// The exception is reuse within ReuseGrace of the rotation: a browser sending
+// several requests with the same expired session renews it in each of them. Those
+// requests get the successor already issued to the first one, or, when another
+// instance rotated the token, a new pair in the same family. A successor is handed
+// out again only while its family is live, so logging out ends the grace at once.
+type RefreshSessionUseCase struct {
+	UserRepository         domain.UserRepository
+	AccessTokens           domain.AccessTokenService
+	RefreshTokenRepository domain.RefreshTokenRepository
+	RefreshTTL             time.Duration
+	UnverifiedPolicy       UnverifiedLoginPolicy // Same policy as at login
+	ReuseGrace             time.Duration         // Zero treats any reuse as theft
+	Rotations              *RefreshRotations     // Shared with EndSessionUseCase; nil skips sharing successors
+}
+
+type RefreshSessionRequest struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token" binding:"required"`
+}
+
+func (uc *RefreshSessionUseCase) Execute(req RefreshSessionRequest) (*domain.TokenPair, error) {
+	tokenHash := hashToken(req.RefreshToken)
+	if uc.ReuseGrace <= 0 || uc.Rotations == nil {
+		pair, _, err := uc.rotate(tokenHash)
+		return pair, err
+	}
+	rotation, first := uc.Rotations.begin(tokenHash, time.Now())
+	if first {
+		rotation.pair, rotation.familyID, rotation.err = uc.rotate(tokenHash)
+		uc.Rotations.finish(rotation, time.Now().Add(uc.ReuseGrace))
+		return rotation.pair, rotation.err
+	}
+	<-rotation.done
+	if rotation.err != nil {
+		return nil, rotation.err
+	}
+	// The family may have been revoked since, e.g. by logging out
+	current, err := uc.RefreshTokenRepository.FindByHash(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	return rotation.pair, nil
+}
+
+// rotate marks the token used and issues its successor. It also returns the family
+// of the token, once known.
+func (uc *RefreshSessionUseCase) rotate(tokenHash string) (*domain.TokenPair, string, error) {
+	stored, err := uc.RefreshTokenRepository.FindByHash(tokenHash)
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	if stored == nil || stored.RevokedAt != nil || !stored.ExpiresAt.After(now) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	rotated, err := uc.RefreshTokenRepository.MarkUsed(stored.ID, now)
+	if err != nil {
+		return nil, stored.FamilyID, err
+	}
+	if !rotated {
+		concurrent, err := uc.rotatedWithinGrace(tokenHash, now)
+		if err != nil {
+			return nil, stored.FamilyID, err
+		}
+		if !concurrent {
+			// Reuse of a rotated token: assume theft
+			if err := uc.RefreshTokenRepository.RevokeFamily(stored.FamilyID, now); err != nil {
+				return nil, stored.FamilyID, err
+			}
+			uc.Rotations.EvictFamily(stored.FamilyID)
+			return nil, stored.FamilyID, ErrInvalidRefreshToken
+		}
+	}
+
+	user, err := uc.UserRepository.FindByID(stored.UserID)
+	if err != nil {
+		return nil, stored.FamilyID, err
+	}
+	// The password was reset since this session started
+	if user == nil || user.SessionVersion != stored.SessionVersion {
+		return nil, stored.FamilyID, ErrInvalidRefreshToken
+	}
+	if err := applyUnverifiedPolicy(uc.UnverifiedPolicy, user); err != nil {
+		return nil, stored.FamilyID, err
+	}
+	pair, err := issueTokenPair(uc.AccessTokens, uc.RefreshTokenRepository, uc.RefreshTTL, user, stored.FamilyID)
+	return pair, stored.FamilyID, err
+}
+
+// rotatedWithinGrace reports whether a token MarkUsed refused was rotated moments
+// ago rather than revoked or used long before.
+func (uc *RefreshSessionUseCase) rotatedWithinGrace(tokenHash string, now time.Time) (bool, error) {
+	if uc.ReuseGrace <= 0 {
+		return false, nil
+	}
+	current, err := uc.RefreshTokenRepository.FindByHash(tokenHash)
+	if err != nil {
+		return false, err
+	}
+	return current != nil && current.RevokedAt == nil && current.UsedAt != nil &&
+		now.Sub(*current.UsedAt) <= uc.ReuseGrace, nil
+}
+
+// RefreshRotations remembers the outcome of recent refreshes by token hash, so
+// concurrent refreshes with the same token wait for the first and share its pair.
+// The zero value is ready to use; all methods accept a nil receiver.
+type RefreshRotations struct {
+	mu      sync.Mutex
+	entries map[string]*rotation
+}
+
+type rotation struct {
+	done      chan struct{} // Closed once pair, familyID and err are set
+	pair      *domain.TokenPair
+	familyID  string
+	err       error
+	expiresAt time.Time // Zero while in progress
+}
+
+// begin returns the rotation of the token, and true if the caller has to perform
+// it and then call finish.
+func (c *RefreshRotations) begin(tokenHash string, now time.Time) (*rotation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for hash, r := range c.entries {
+		if !r.expiresAt.IsZero() && !r.expiresAt.After(now) {
+			delete(c.entries, hash)
+		}
+	}
+	if r, ok := c.entries[tokenHash]; ok {
+		return r, false
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]*rotation)
+	}
+	r := &rotation{done: make(chan struct{})}
+	c.entries[tokenHash] = r
+	return r, true
+}
+
+// finish publishes the outcome to the waiting requests and keeps it until expiresAt.
+func (c *RefreshRotations) finish(r *rotation, expiresAt time.Time) {
+	c.mu.Lock()
+	r.expiresAt = expiresAt
+	c.mu.Unlock()
+	close(r.done)
+}
+
+// EvictFamily forgets the finished refreshes of a revoked family, so their pairs
+// are not handed out again.
+func (c *RefreshRotations) EvictFamily(familyID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for hash, r := range c.entries {
+		if !r.expiresAt.IsZero() && r.familyID == familyID {
+			delete(c.entries, hash)
+		}
+	}
+}
+
+// EndSessionUseCase logs out: the access token stops working immediately and the
+// refresh token, if given, can no longer be rotated.
+type EndSessionUseCase struct {
+	RefreshTokenRepository domain.RefreshTokenRepository
+	TokenDenylist          domain.TokenDenylist
+	Rotations              *RefreshRotations // Same as RefreshSessionUseCase's, if any
+}
+
+type EndSessionRequest struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token"`
+}
+
+func (uc *EndSessionUseCase) Execute(claims *domain.AccessClaims, req EndSessionRequest) error {
+	if err := uc.TokenDenylist.Add(claims.TokenID, claims.ExpiresAt); err != nil {
+		return err
+	}
+	if req.RefreshToken == "" {
+		return nil
+	}
+	stored, err := uc.RefreshTokenRepository.FindByHash(hashToken(req.RefreshToken))
+	if err != nil {
+		return err
+	}
+	// Nobody can log out someone else's session
+	if stored == nil || stored.UserID != claims.UserID {
+		return nil
+	}
+	if err := uc.RefreshTokenRepository.RevokeFamily(stored.FamilyID, time.Now()); err != nil {
+		return err
+	}
+	uc.Rotations.EvictFamily(stored.FamilyID)
+	return nil
+}
+
+// issueTokenPair signs an access token and stores a new refresh token in the family.
+func issueTokenPair(
+	accessTokens domain.AccessTokenService,
+	refreshTokens domain.RefreshTokenRepository,
+	refreshTTL time.Duration,
+	user *domain.User,
+	familyID string,
+) (*domain.TokenPair, error) {
+	accessToken, claims, err := accessTokens.Issue(user)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, hash, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	stored := &domain.RefreshToken{
+		UserID:         user.ID,
+		TokenHash:      hash,
+		FamilyID:       familyID,
+		SessionVersion: user.SessionVersion,
+		ExpiresAt:      now.Add(refreshTTL),
+		CreatedAt:      now,
+	}
+	if err := refreshTokens.Create(stored); err != nil {
+		return nil, err
+	}
+	return &domain.TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  claims.ExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
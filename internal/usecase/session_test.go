@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAccessTokenService is a mock implementation of domain.AccessTokenService
+type MockAccessTokenService struct {
+	mock.Mock
+}
+
+func (m *MockAccessTokenService) Issue(user *domain.User) (string, *domain.AccessClaims, error) {
+	args := m.Called(user)
+	claims, _ := args.Get(1).(*domain.AccessClaims)
+	return args.String(0), claims, args.Error(2)
+}
+
+func (m *MockAccessTokenService) Parse(token string) (*domain.AccessClaims, error) {
+	args := m.Called(token)
+	claims, _ := args.Get(0).(*domain.AccessClaims)
+	return claims, args.Error(1)
+}
+
+// MockRefreshTokenRepository is a mock implementation of domain.RefreshTokenRepository
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) FindByHash(tokenHash string) (*domain.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) MarkUsed(id uint, at time.Time) (bool, error) {
+	args := m.Called(id, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RevokeFamily(familyID string, at time.Time) error {
+	args := m.Called(familyID, at)
+	return args.Error(0)
+}
+
+// MockTokenDenylist is a mock implementation of domain.TokenDenylist
+type MockTokenDenylist struct {
+	mock.Mock
+}
+
+func (m *MockTokenDenylist) Add(tokenID string, expiresAt time.Time) error {
+	args := m.Called(tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenDenylist) Contains(tokenID string) (bool, error) {
+	args := m.Called(tokenID)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestStartSessionUseCase_Execute(t *testing.T) {
+	mockTokens := new(MockAccessTokenService)
+	mockRefreshRepo := new(MockRefreshTokenRepository)
+	usecase := &StartSessionUseCase{AccessTokens: mockTokens, RefreshTokenRepository: mockRefreshRepo, RefreshTTL: time.Hour}
+
+	user := &domain.User{ID: 1, SessionVersion: 2}
+	expires := time.Now().Add(15 * time.Minute)
+	var stored *domain.RefreshToken
+	mockTokens.On("Issue", user).Return("access", &domain.AccessClaims{TokenID: "jti", ExpiresAt: expires}, nil).Once()
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*domain.RefreshToken)
+	}).Return(nil).Once()
+
+	pair, err := usecase.Execute(user)
+	assert.NoError(t, err)
+	assert.Equal(t, "access", pair.AccessToken)
+	assert.Equal(t, expires, pair.AccessExpiresAt)
+	// Only the hash is stored, tied to the current session version
+	assert.Equal(t, hashToken(pair.RefreshToken), stored.TokenHash)
+	assert.Equal(t, 2, stored.SessionVersion)
+	assert.NotEmpty(t, stored.FamilyID)
+
+	mockTokens.AssertExpectations(t)
+	mockRefreshRepo.AssertExpectations(t)
+}
+
+func TestRefreshSessionUseCase_Execute(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockTokens := new(MockAccessTokenService)
+	mockRefreshRepo := new(MockRefreshTokenRepository)
+	usecase := &RefreshSessionUseCase{
+		UserRepository:         mockUserRepo,
+		AccessTokens:           mockTokens,
+		RefreshTokenRepository: mockRefreshRepo,
+		RefreshTTL:             time.Hour,
+		UnverifiedPolicy:       UnverifiedLoginLimit,
+	}
+	valid := func() *domain.RefreshToken {
+		return &domain.RefreshToken{ID: 7, UserID: 1, FamilyID: "fam", SessionVersion: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	}
+	verifiedAt := time.Now()
+
+	// Test case: Token is rotated within its family
+	mockRefreshRepo.On("FindByHash", hashToken("r1")).Return(valid(), nil).Once()
+	mockRefreshRepo.On("MarkUsed", uint(7), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	mockUserRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, SessionVersion: 1, Role: domain.RoleEditor, EmailVerifiedAt: &verifiedAt}, nil).Once()
+	mockTokens.On("Issue", mock.AnythingOfType("*domain.User")).Return("access", &domain.AccessClaims{}, nil).Once()
+	mockRefreshRepo.On("Create", mock.MatchedBy(func(t *domain.RefreshToken) bool { return t.FamilyID == "fam" })).Return(nil).Once()
+
+	pair, err := usecase.Execute(RefreshSessionRequest{RefreshToken: "r1"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, "r1", pair.RefreshToken)
+
+	// Test case: Reusing a rotated token revokes the family
+	mockRefreshRepo.On("FindByHash", hashToken("r1")).Return(valid(), nil).Once()
+	mockRefreshRepo.On("MarkUsed", uint(7), mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+	mockRefreshRepo.On("RevokeFamily", "fam", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	pair, err = usecase.Execute(RefreshSessionRequest{RefreshToken: "r1"})
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	assert.Nil(t, pair)
+
+	// Test case: Password was reset since login
+	mockRefreshRepo.On("FindByHash", hashToken("r2")).Return(valid(), nil).Once()
+	mockRefreshRepo.On("MarkUsed", uint(7), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	mockUserRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, SessionVersion: 2}, nil).Once()
+
+	pair, err = usecase.Execute(RefreshSessionRequest{RefreshToken: "r2"})
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	assert.Nil(t, pair)
+
+	// Test case: Expired token
+	expired := valid()
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	mockRefreshRepo.On("FindByHash", hashToken("r3")).Return(expired, nil).Once()
+
+	pair, err = usecase.Execute(RefreshSessionRequest{RefreshToken: "r3"})
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	assert.Nil(t, pair)
+
+	mockUserRepo.AssertExpectations(t)
+	mockTokens.AssertExpectations(t)
+	mockRefreshRepo.AssertExpectations(t)
+}
+
+func TestRefreshSessionUseCase_Execute_ReuseGrace(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockTokens := new(MockAccessTokenService)
+	mockRefreshRepo := new(MockRefreshTokenRepository)
+	usecase := &RefreshSessionUseCase{
+		UserRepository:         mockUserRepo,
+		AccessTokens:           mockTokens,
+		RefreshTokenRepository: mockRefreshRepo,
+		RefreshTTL:             time.Hour,
+		UnverifiedPolicy:       UnverifiedLoginAllow,
+		ReuseGrace:             10 * time.Second,
+		Rotations:              &RefreshRotations{},
+	}
+	token := func(usedAgo time.Duration) *domain.RefreshToken {
+		t := &domain.RefreshToken{ID: 7, UserID: 1, FamilyID: "fam", SessionVersion: 1, ExpiresAt: time.Now().Add(time.Hour)}
+		if usedAgo > 0 {
+			usedAt := time.Now().Add(-usedAgo)
+			t.UsedAt = &usedAt
+		}
+		return t
+	}
+
+	// Test case: Requests with the same token get the same successor
+	mockRefreshRepo.On("FindByHash", hashToken("r1")).Return(token(0), nil).Twice()
+	mockRefreshRepo.On("MarkUsed", uint(7), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	mockUserRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, SessionVersion: 1}, nil).Once()
+	mockTokens.On("Issue", mock.AnythingOfType("*domain.User")).Return("access", &domain.AccessClaims{}, nil).Once()
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil).Once()
+
+	first, err := usecase.Execute(RefreshSessionRequest{RefreshToken: "r1"})
+	assert.NoError(t, err)
+	second, err := usecase.Execute(RefreshSessionRequest{RefreshToken: "r1"})
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+
+	// Test case: Token rotated moments ago elsewhere gets a new pair in the family
+	mockRefreshRepo.On("FindByHash", hashToken("r2")).Return(token(time.Second), nil).Twice()
+	mockRefreshRepo.On("MarkUsed", uint(7), mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+	mockUserRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, SessionVersion: 1}, nil).Once()
+	mockTokens.On("Issue", mock.AnythingOfType("*domain.User")).Return("access", &domain.AccessClaims{}, nil).Once()
+	mockRefreshRepo.On("Create", mock.MatchedBy(func(t *domain.RefreshToken) bool { return t.FamilyID == "fam" })).Return(nil).Once()
+
+	pair, err := usecase.Execute(RefreshSessionRequest{RefreshToken: "r2"})
+	assert.NoError(t, err)
+	assert.NotNil(t, pair)
+
+	// Test case: Reuse after the grace period still revokes the family
+	mockRefreshRepo.On("FindByHash", hashToken("r3")).Return(token(time.Minute), nil).Twice()
+	mockRefreshRepo.On("MarkUsed", uint(7), mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+	mockRefreshRepo.On("RevokeFamily", "fam", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	pair, err = usecase.Execute(RefreshSessionRequest{RefreshToken: "r3"})
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	assert.Nil(t, pair)
+
+	mockUserRepo.AssertExpectations(t)
+	mockTokens.AssertExpectations(t)
+	mockRefreshRepo.AssertExpectations(t)
+}
+
+func TestRefreshSessionUseCase_Execute_AfterLogout(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockTokens := new(MockAccessTokenService)
+	mockRefreshRepo := new(MockRefreshTokenRepository)
+	mockDenylist := new(MockTokenDenylist)
+	rotations := &RefreshRotations{}
+	refresh := &RefreshSessionUseCase{
+		UserRepository:         mockUserRepo,
+		AccessTokens:           mockTokens,
+		RefreshTokenRepository: mockRefreshRepo,
+		RefreshTTL:             time.Hour,
+		UnverifiedPolicy:       UnverifiedLoginAllow,
+		ReuseGrace:             10 * time.Second,
+		Rotations:              rotations,
+	}
+	logout := &EndSessionUseCase{RefreshTokenRepository: mockRefreshRepo, TokenDenylist: mockDenylist, Rotations: rotations}
+	claims := &domain.AccessClaims{TokenID: "jti", UserID: 1, ExpiresAt: time.Now().Add(time.Minute)}
+	live := &domain.RefreshToken{ID: 7, UserID: 1, FamilyID: "fam", SessionVersion: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	revokedAt := time.Now()
+	revoked := &domain.RefreshToken{ID: 7, UserID: 1, FamilyID: "fam", SessionVersion: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+
+	mockRefreshRepo.On("MarkUsed", uint(7), mock.AnythingOfType("time.Time")).Return(true, nil).Twice()
+	mockUserRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, SessionVersion: 1}, nil).Twice()
+	mockTokens.On("Issue", mock.AnythingOfType("*domain.User")).Return("access", &domain.AccessClaims{}, nil).Twice()
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil).Twice()
+
+	// Test case: Logging out forgets the successor, so the old token is refused
+	mockRefreshRepo.On("FindByHash", hashToken("r1")).Return(live, nil).Twice()
+	mockDenylist.On("Add", "jti", claims.ExpiresAt).Return(nil).Once()
+	mockRefreshRepo.On("RevokeFamily", "fam", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	pair, err := refresh.Execute(RefreshSessionRequest{RefreshToken: "r1"})
+	assert.NoError(t, err)
+	assert.NotNil(t, pair)
+	assert.NoError(t, logout.Execute(claims, EndSessionRequest{RefreshToken: "r1"}))
+
+	mockRefreshRepo.On("FindByHash", hashToken("r1")).Return(revoked, nil).Once()
+	pair, err = refresh.Execute(RefreshSessionRequest{RefreshToken: "r1"})
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	assert.Nil(t, pair)
+
+	// Test case: A family revoked elsewhere is not served from the cache either
+	mockRefreshRepo.On("FindByHash", hashToken("r2")).Return(live, nil).Once()
+
+	pair, err = refresh.Execute(RefreshSessionRequest{RefreshToken: "r2"})
+	assert.NoError(t, err)
+	assert.NotNil(t, pair)
+
+	mockRefreshRepo.On("FindByHash", hashToken("r2")).Return(revoked, nil).Once()
+	pair, err = refresh.Execute(RefreshSessionRequest{RefreshToken: "r2"})
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	assert.Nil(t, pair)
+
+	mockUserRepo.AssertExpectations(t)
+	mockTokens.AssertExpectations(t)
+	mockRefreshRepo.AssertExpectations(t)
+	mockDenylist.AssertExpectations(t)
+}
+
+func TestEndSessionUseCase_Execute(t *testing.T) {
+	mockRefreshRepo := new(MockRefreshTokenRepository)
+	mockDenylist := new(MockTokenDenylist)
+	usecase := &EndSessionUseCase{RefreshTokenRepository: mockRefreshRepo, TokenDenylist: mockDenylist}
+
+	claims := &domain.AccessClaims{TokenID: "jti", UserID: 1, ExpiresAt: time.Now().Add(time.Minute)}
+
+	// Test case: Access token is denied and the refresh family revoked
+	mockDenylist.On("Add", "jti", claims.ExpiresAt).Return(nil).Twice()
+	mockRefreshRepo.On("FindByHash", hashToken("mine")).Return(&domain.RefreshToken{UserID: 1, FamilyID: "fam"}, nil).Once()
+	mockRefreshRepo.On("RevokeFamily", "fam", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	err := usecase.Execute(claims, EndSessionRequest{RefreshToken: "mine"})
+	assert.NoError(t, err)
+
+	// Test case: Someone else's refresh token is left alone
+	mockRefreshRepo.On("FindByHash", hashToken("theirs")).Return(&domain.RefreshToken{UserID: 2, FamilyID: "other"}, nil).Once()
+
+	err = usecase.Execute(claims, EndSessionRequest{RefreshToken: "theirs"})
+	assert.NoError(t, err)
+
+	mockRefreshRepo.AssertExpectations(t)
+	mockDenylist.AssertExpectations(t)
+}
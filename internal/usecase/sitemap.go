@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+)
+
+// GetSitemapUseCase lists the public URLs of the blog for search engines: the home
+// page, every category and every published post. When there are more URLs than fit
+// in one sitemap file they are split into numbered parts.
+type GetSitemapUseCase struct {
+	BlogRepository     domain.BlogRepository
+	CategoryRepository domain.CategoryRepository
+	MaxURLs            int // URLs per sitemap file; defaults to domain.SitemapMaxURLs
+}
+
+func (uc *GetSitemapUseCase) maxURLs() int {
+	if uc.MaxURLs <= 0 || uc.MaxURLs > domain.SitemapMaxURLs {
+		return domain.SitemapMaxURLs
+	}
+	return uc.MaxURLs
+}
+
+// Parts returns how many sitemap files are needed to list every URL. More than one
+// means the sitemap has to be served as a sitemap index.
+func (uc *GetSitemapUseCase) Parts() (int, error) {
+	categories, err := uc.CategoryRepository.FindAll()
+	if err != nil {
+		return 0, err
+	}
+	return uc.parts(len(categories))
+}
+
+func (uc *GetSitemapUseCase) parts(categoryCount int) (int, error) {
+	posts, err := uc.BlogRepository.Count(domain.BlogFilter{PublishedOnly: true})
+	if err != nil {
+		return 0, err
+	}
+	total := int64(1+categoryCount) + posts // The home page comes first
+	perPart := int64(uc.maxURLs())
+	return int((total + perPart - 1) / perPart), nil
+}
+
+// Execute returns the URLs in the given 1-based sitemap part: the home page and
+// categories first, then published posts in a stable order.
+func (uc *GetSitemapUseCase) Execute(part int) ([]domain.SitemapEntry, error) {
+	categories, err := uc.CategoryRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	parts, err := uc.parts(len(categories))
+	if err != nil {
+		return nil, err
+	}
+	if part < 1 || part > parts {
+		return nil, domain.ErrNotFound
+	}
+
+	fixed := make([]domain.SitemapEntry, 0, 1+len(categories))
+	fixed = append(fixed, domain.SitemapEntry{Path: "/"})
+	for _, category := range categories {
+		fixed = append(fixed, domain.SitemapEntry{Path: "/category/" + category.Slug, LastMod: category.UpdatedAt})
+	}
+
+	perPart := uc.maxURLs()
+	start, end := (part-1)*perPart, part*perPart
+	var entries []domain.SitemapEntry
+	if start < len(fixed) {
+		entries = append(entries, fixed[start:min(end, len(fixed))]...)
+	}
+
+	// Posts fill whatever room the fixed pages leave in this part
+	offset := max(start, len(fixed)) - len(fixed)
+	limit := end - max(start, len(fixed))
+	if limit == 0 {
+		return entries, nil
+	}
+	posts, err := uc.BlogRepository.FindSitemapPage(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		entries = append(entries, domain.SitemapEntry{Path: "/post/" + post.Slug, LastMod: post.TimeUpdate})
+	}
+	return entries, nil
+}
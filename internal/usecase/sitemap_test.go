@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSitemapUseCase_Execute(t *testing.T) {
+	mockBlogRepo := new(MockBlogRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	usecase := &GetSitemapUseCase{BlogRepository: mockBlogRepo, CategoryRepository: mockCategoryRepo, MaxURLs: 3}
+
+	updated := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	categories := []domain.Category{{ID: 1, Slug: "go", UpdatedAt: updated}, {ID: 2, Slug: "sql"}}
+	mockCategoryRepo.On("FindAll").Return(categories, nil)
+	// Home page + 2 categories + 5 posts = 8 URLs, 3 per part
+	mockBlogRepo.On("Count", domain.BlogFilter{PublishedOnly: true}).Return(int64(5), nil)
+
+	parts, err := usecase.Parts()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, parts)
+
+	// The first part is filled by the fixed pages, no posts are loaded
+	entries, err := usecase.Execute(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.SitemapEntry{
+		{Path: "/"},
+		{Path: "/category/go", LastMod: updated},
+		{Path: "/category/sql"},
+	}, entries)
+
+	mockBlogRepo.On("FindSitemapPage", 3, 3).Return([]domain.Blog{{ID: 9, Slug: "last", TimeUpdate: updated}}, nil).Once()
+	entries, err = usecase.Execute(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.SitemapEntry{{Path: "/post/last", LastMod: updated}}, entries)
+
+	// Parts past the end do not exist
+	entries, err = usecase.Execute(4)
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Nil(t, entries)
+
+	mockBlogRepo.AssertExpectations(t)
+	mockCategoryRepo.AssertExpectations(t)
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// slugPattern accepts lowercase words of letters and digits joined by single hyphens, e.g. "go-concurrency".
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// isValidSlug reports whether s can be used as a URL slug.
+func isValidSlug(s string) bool {
+	return slugPattern.MatchString(s)
+}
+
+// slugify turns a free-form name such as "Go Generics" into a slug such as "go-generics".
+// Letters outside ASCII are kept, so Cyrillic tag names stay readable in URLs.
+func slugify(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
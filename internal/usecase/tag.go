@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"programming_blog_go/internal/domain"
+)
+
+// GetBlogPostsByTagUseCase retrieves a page of blog posts carrying a tag.
+type GetBlogPostsByTagUseCase struct {
+	BlogRepository domain.BlogRepository
+	TagRepository  domain.TagRepository
+}
+
+// Execute retrieves a single page of posts with the tag, newest first, together with the tag itself.
+func (uc *GetBlogPostsByTagUseCase) Execute(tagSlug string, publishedOnly bool, req domain.PageRequest) (*domain.Tag, *domain.BlogPage, error) {
+	tag, err := uc.TagRepository.FindBySlug(tagSlug)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tag == nil {
+		return nil, nil, domain.ErrNotFound
+	}
+
+	page, err := paginate(uc.BlogRepository, domain.BlogFilter{PublishedOnly: publishedOnly, TagID: tag.ID}, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tag, page, nil
+}
+
+// GetTagCloudUseCase retrieves the tags used by published posts, weighted by popularity.
+type GetTagCloudUseCase struct {
+	TagRepository domain.TagRepository
+}
+
+// tagCloudWeights is the number of distinct sizes in the tag cloud.
+const tagCloudWeights = 5
+
+// Execute retrieves the tag cloud, assigning each tag a weight from 1 to tagCloudWeights.
+func (uc *GetTagCloudUseCase) Execute() ([]domain.TagCount, error) {
+	counts, err := uc.TagRepository.FindPublishedCounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var maxCount int64
+	for _, tc := range counts {
+		if tc.PostCount > maxCount {
+			maxCount = tc.PostCount
+		}
+	}
+	for i := range counts {
+		counts[i].Weight = 1
+		if maxCount > 1 {
+			counts[i].Weight = 1 + int((counts[i].PostCount-1)*(tagCloudWeights-1)/(maxCount-1))
+		}
+	}
+	return counts, nil
+}
+
+// resolveTags turns tag names from a request into stored tags, creating the ones
+// that do not exist yet. Names that slugify to the same slug are merged.
+func resolveTags(repo domain.TagRepository, names []string) ([]domain.Tag, error) {
+	tags := []domain.Tag{}
+	seen := make(map[string]bool)
+	for _, raw := range names {
+		// Form submissions send a single comma-separated field
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			slug := slugify(name)
+			if slug == "" {
+				return nil, domain.ErrInvalidInput
+			}
+			if seen[slug] {
+				continue
+			}
+			seen[slug] = true
+
+			tag, err := findOrCreateTag(repo, name, slug)
+			if err != nil {
+				return nil, err
+			}
+			if tag == nil {
+				return nil, domain.ErrNotFound
+			}
+			tags = append(tags, *tag)
+		}
+	}
+	return tags, nil
+}
+
+// findOrCreateTag returns the tag with the slug, creating it if needed.
+func findOrCreateTag(repo domain.TagRepository, name, slug string) (*domain.Tag, error) {
+	tag, err := repo.FindBySlug(slug)
+	if err != nil || tag != nil {
+		return tag, err
+	}
+
+	tag = &domain.Tag{Name: name, Slug: slug, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	err = repo.Create(tag)
+	if err == domain.ErrAlreadyExists {
+		// Created concurrently by another request; use that one
+		return repo.FindBySlug(slug)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTagRepository is a mock implementation of domain.TagRepository
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) Create(tag *domain.Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) FindByID(id uint) (*domain.Tag, error) {
+	args := m.Called(id)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) FindBySlug(slug string) (*domain.Tag, error) {
+	args := m.Called(slug)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) FindAll() ([]domain.Tag, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) FindPublishedCounts() ([]domain.TagCount, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.TagCount), args.Error(1)
+}
+
+func (m *MockTagRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "go-generics", slugify("Go Generics"))
+	assert.Equal(t, "c-tips", slugify("  C++ / tips "))
+	assert.Equal(t, "базы-данных", slugify("Базы данных"))
+	assert.Equal(t, "", slugify("!!!"))
+}
+
+func TestResolveTags(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+
+	// Existing tag is reused, new tag is created, duplicates are merged
+	mockRepo.On("FindBySlug", "go").Return(&domain.Tag{ID: 1, Name: "Go", Slug: "go"}, nil).Once()
+	mockRepo.On("FindBySlug", "postgresql").Return(nil, nil).Once()
+	mockRepo.On("Create", mock.AnythingOfType("*domain.Tag")).Run(func(args mock.Arguments) {
+		args.Get(0).(*domain.Tag).ID = 2
+	}).Return(nil).Once()
+
+	tags, err := resolveTags(mockRepo, []string{"Go, PostgreSQL", "go"})
+	assert.NoError(t, err)
+	assert.Len(t, tags, 2)
+	assert.Equal(t, uint(1), tags[0].ID)
+	assert.Equal(t, uint(2), tags[1].ID)
+	assert.Equal(t, "PostgreSQL", tags[1].Name)
+
+	// Names without any letters or digits are rejected
+	tags, err = resolveTags(mockRepo, []string{"***"})
+	assert.Equal(t, domain.ErrInvalidInput, err)
+	assert.Nil(t, tags)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetBlogPostsByTagUseCase_Execute(t *testing.T) {
+	mockBlogRepo := new(MockBlogRepository)
+	mockTagRepo := new(MockTagRepository)
+	usecase := &GetBlogPostsByTagUseCase{BlogRepository: mockBlogRepo, TagRepository: mockTagRepo}
+
+	tag := &domain.Tag{ID: 4, Name: "Go", Slug: "go"}
+	filter := domain.BlogFilter{PublishedOnly: true, TagID: 4}
+	expectedBlogs := []domain.Blog{{ID: 1, Title: "Go Post", Tags: []domain.Tag{*tag}}}
+
+	// Test case: Tag found
+	mockTagRepo.On("FindBySlug", "go").Return(tag, nil).Once()
+	mockBlogRepo.On("Count", filter).Return(int64(1), nil).Once()
+	mockBlogRepo.On("FindPage", filter, 0, domain.DefaultPageSize).Return(expectedBlogs, nil).Once()
+
+	foundTag, page, err := usecase.Execute("go", true, domain.PageRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, tag, foundTag)
+	assert.Equal(t, expectedBlogs, page.Posts)
+
+	// Test case: Tag not found
+	mockTagRepo.On("FindBySlug", "rust").Return(nil, nil).Once()
+
+	foundTag, page, err = usecase.Execute("rust", true, domain.PageRequest{})
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Nil(t, foundTag)
+	assert.Nil(t, page)
+
+	mockBlogRepo.AssertExpectations(t)
+	mockTagRepo.AssertExpectations(t)
+}
+
+func TestGetTagCloudUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	usecase := &GetTagCloudUseCase{TagRepository: mockRepo}
+
+	mockRepo.On("FindPublishedCounts").Return([]domain.TagCount{
+		{Tag: domain.Tag{Slug: "rare"}, PostCount: 1},
+		{Tag: domain.Tag{Slug: "medium"}, PostCount: 5},
+		{Tag: domain.Tag{Slug: "popular"}, PostCount: 9},
+	}, nil).Once()
+
+	cloud, err := usecase.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cloud[0].Weight)
+	assert.Equal(t, 3, cloud[1].Weight)
+	assert.Equal(t, 5, cloud[2].Weight)
+
+	mockRepo.AssertExpectations(t)
+}
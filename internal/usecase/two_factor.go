@@ -0,0 +1,437 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/utils"
+)
+
+var (
+	ErrInvalidTwoFactorCode      = errors.New("invalid two-factor code")
+	ErrInvalidTwoFactorChallenge = errors.New("invalid or expired login challenge")
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotSetUp         = errors.New("two-factor authentication is not set up")
+)
+
+// RecoveryCodeCount is how many recovery codes a user gets at a time.
+const RecoveryCodeCount = 10
+
+// totpSkew is how many 30-second steps a code may be early or late, for clock drift.
+const totpSkew = 1
+
+// recoveryCodeEncoding writes recovery codes in lowercase base32, which avoids
+// characters that are easy to mistake for each other.
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// TwoFactorChallenge issues the short-lived tokens that connect the two login
+// steps: after the password is checked the client receives one instead of a
+// session and trades it, together with a TOTP or recovery code, for tokens.
+// Like email verification links they are stateless and signed with a key
+// derived for this purpose. A password reset invalidates them via the session version.
+type TwoFactorChallenge struct {
+	Secret []byte
+	TTL    time.Duration
+}
+
+// Token returns a challenge token for the user.
+func (ch *TwoFactorChallenge) Token(user *domain.User, now time.Time) (string, time.Time) {
+	expires := now.Add(ch.TTL)
+	payload := fmt.Sprintf("%d:%d:%d", user.ID, user.SessionVersion, expires.Unix())
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(ch.sign(encoded)), expires
+}
+
+// Parse checks the token's signature and expiry and returns what it vouches for.
+func (ch *TwoFactorChallenge) Parse(token string, now time.Time) (userID uint, sessionVersion int, err error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, 0, ErrInvalidTwoFactorChallenge
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, ch.sign(encoded)) {
+		return 0, 0, ErrInvalidTwoFactorChallenge
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, 0, ErrInvalidTwoFactorChallenge
+	}
+
+	parts := strings.Split(string(payload), ":")
+	if len(parts) != 3 {
+		return 0, 0, ErrInvalidTwoFactorChallenge
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, ErrInvalidTwoFactorChallenge
+	}
+	sv, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, ErrInvalidTwoFactorChallenge
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return 0, 0, ErrInvalidTwoFactorChallenge
+	}
+	return uint(id), sv, nil
+}
+
+func (ch *TwoFactorChallenge) sign(encoded string) []byte {
+	key := hmac.New(sha256.New, ch.Secret)
+	key.Write([]byte("two-factor-challenge"))
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// secondFactor checks TOTP and recovery codes for a user.
+type secondFactor struct {
+	users         domain.UserRepository
+	recoveryCodes domain.RecoveryCodeRepository
+	cipher        domain.SecretCipher
+}
+
+// checkTOTP verifies a code against the user's secret, pending or enabled, and
+// uses up its time step.
+func (f secondFactor) checkTOTP(user *domain.User, code string, now time.Time) error {
+	if user.TOTPSecret == "" {
+		return ErrTwoFactorNotSetUp
+	}
+	secret, err := f.cipher.Decrypt(user.TOTPSecret)
+	if err != nil {
+		return err
+	}
+	step, ok := utils.ValidateTOTP(secret, strings.ReplaceAll(code, " ", ""), now, totpSkew)
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	fresh, err := f.users.AdvanceTOTPStep(user.ID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidTwoFactorCode
+	}
+	// Keep the copy in sync, or saving it later would store the old step again
+	user.TOTPLastStep = step
+	return nil
+}
+
+// check accepts either a TOTP code or an unused recovery code.
+func (f secondFactor) check(user *domain.User, code string, now time.Time) error {
+	code = strings.TrimSpace(code)
+	if len(strings.ReplaceAll(code, " ", "")) == utils.TOTPDigits {
+		return f.checkTOTP(user, code, now)
+	}
+	consumed, err := f.recoveryCodes.Consume(user.ID, hashToken(normalizeRecoveryCode(code)), now)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// checkThrottled is check for actions on a signed-in account. Wrong codes count
+// as failed logins, so a stolen session cannot be used to guess at them.
+func (f secondFactor) checkThrottled(throttle *LoginThrottle, user *domain.User, code, clientIP string, now time.Time) error {
+	attempt, err := throttle.Attempt(user.Username, clientIP, now)
+	if err != nil {
+		return err
+	}
+	if err := f.check(user, code, now); err != nil {
+		// Only a wrong code counts as a failure
+		if err != ErrInvalidTwoFactorCode {
+			attempt.Release()
+		}
+		return err
+	}
+	return attempt.Succeeded()
+}
+
+// issueRecoveryCodes replaces the user's recovery codes and returns the new ones,
+// which are shown to the user only this once.
+func (f secondFactor) issueRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := recoveryCodeEncoding.EncodeToString(b)[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashToken(raw)
+	}
+	if err := f.recoveryCodes.ReplaceAll(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode forgives case, spaces and dashes when a code is typed in.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// findTwoFactorUser loads the actor's account for the enrollment use cases.
+// Two-factor authentication is offered to the roles that can change content.
+func findTwoFactorUser(users domain.UserRepository, actor Actor) (*domain.User, error) {
+	if !actor.Can(domain.PermissionWritePosts) {
+		return nil, domain.ErrForbidden
+	}
+	user, err := users.FindByID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// TwoFactorStatus tells the owner of an account whether logging in asks for a code.
+type TwoFactorStatus struct {
+	Enabled   bool       `json:"enabled"`
+	EnabledAt *time.Time `json:"enabled_at,omitempty"`
+}
+
+// GetTwoFactorStatusUseCase reports the actor's own two-factor status; it is not
+// part of the user JSON that admins and other endpoints see.
+type GetTwoFactorStatusUseCase struct {
+	UserRepository domain.UserRepository
+}
+
+func (uc *GetTwoFactorStatusUseCase) Execute(actor Actor) (*TwoFactorStatus, error) {
+	user, err := findTwoFactorUser(uc.UserRepository, actor)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return &TwoFactorStatus{}, nil
+	}
+	return &TwoFactorStatus{Enabled: true, EnabledAt: user.TOTPEnabledAt}, nil
+}
+
+// TwoFactorSetup is what an authenticator app needs to enroll.
+type TwoFactorSetup struct {
+	Secret string `json:"secret"` // For typing in by hand
+	URI    string `json:"otpauth_uri"`
+}
+
+// SetupTwoFactorUseCase starts enrollment: it stores a new secret that only takes
+// effect once ConfirmTwoFactorUseCase sees a code generated from it.
+type SetupTwoFactorUseCase struct {
+	UserRepository domain.UserRepository
+	SecretCipher   domain.SecretCipher
+	Issuer         string // Shown in the authenticator app, e.g. the site title
+}
+
+func (uc *SetupTwoFactorUseCase) Execute(actor Actor) (*TwoFactorSetup, error) {
+	user, err := findTwoFactorUser(uc.UserRepository, actor)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := uc.SecretCipher.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = encrypted
+	user.TOTPEnabledAt = nil
+	user.UpdatedAt = time.Now()
+	if err := uc.UserRepository.Update(user); err != nil {
+		return nil, err
+	}
+	return &TwoFactorSetup{
+		Secret: utils.EncodeTOTPSecret(secret),
+		URI:    utils.TOTPURI(uc.Issuer, user.Username, secret),
+	}, nil
+}
+
+// TwoFactorCodeRequest carries a TOTP code, or for some operations a recovery code.
+type TwoFactorCodeRequest struct {
+	Code     string `json:"code" form:"code" binding:"required"`
+	ClientIP string `json:"-" form:"-"` // Set by the handler for per-address throttling
+}
+
+// ConfirmTwoFactorUseCase finishes enrollment with a code from the authenticator,
+// proving it was set up correctly, and hands out the first recovery codes.
+type ConfirmTwoFactorUseCase struct {
+	UserRepository         domain.UserRepository
+	RecoveryCodeRepository domain.RecoveryCodeRepository
+	SecretCipher           domain.SecretCipher
+}
+
+func (uc *ConfirmTwoFactorUseCase) Execute(actor Actor, req TwoFactorCodeRequest) ([]string, error) {
+	user, err := findTwoFactorUser(uc.UserRepository, actor)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	factor := secondFactor{uc.UserRepository, uc.RecoveryCodeRepository, uc.SecretCipher}
+	now := time.Now()
+	if err := factor.checkTOTP(user, req.Code, now); err != nil {
+		return nil, err
+	}
+
+	user.TOTPEnabledAt = &now
+	user.UpdatedAt = now
+	if err := uc.UserRepository.Update(user); err != nil {
+		return nil, err
+	}
+	return factor.issueRecoveryCodes(user.ID)
+}
+
+// DisableTwoFactorUseCase turns two-factor authentication off. It asks for a
+// current code so a stolen session alone cannot remove the second factor.
+type DisableTwoFactorUseCase struct {
+	UserRepository         domain.UserRepository
+	RecoveryCodeRepository domain.RecoveryCodeRepository
+	SecretCipher           domain.SecretCipher
+	Throttle               *LoginThrottle // Wrong codes count as failed logins
+}
+
+func (uc *DisableTwoFactorUseCase) Execute(actor Actor, req TwoFactorCodeRequest) error {
+	user, err := findTwoFactorUser(uc.UserRepository, actor)
+	if err != nil {
+		return err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return ErrTwoFactorNotSetUp
+	}
+	factor := secondFactor{uc.UserRepository, uc.RecoveryCodeRepository, uc.SecretCipher}
+	if err := factor.checkThrottled(uc.Throttle, user, req.Code, req.ClientIP, time.Now()); err != nil {
+		return err
+	}
+	return removeTwoFactor(uc.UserRepository, uc.RecoveryCodeRepository, user)
+}
+
+// RegenerateRecoveryCodesUseCase replaces a user's recovery codes, e.g. when most
+// are used up. Like disabling, it asks for a current code.
+type RegenerateRecoveryCodesUseCase struct {
+	UserRepository         domain.UserRepository
+	RecoveryCodeRepository domain.RecoveryCodeRepository
+	SecretCipher           domain.SecretCipher
+	Throttle               *LoginThrottle // Wrong codes count as failed logins
+}
+
+func (uc *RegenerateRecoveryCodesUseCase) Execute(actor Actor, req TwoFactorCodeRequest) ([]string, error) {
+	user, err := findTwoFactorUser(uc.UserRepository, actor)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorNotSetUp
+	}
+	factor := secondFactor{uc.UserRepository, uc.RecoveryCodeRepository, uc.SecretCipher}
+	if err := factor.checkThrottled(uc.Throttle, user, req.Code, req.ClientIP, time.Now()); err != nil {
+		return nil, err
+	}
+	return factor.issueRecoveryCodes(user.ID)
+}
+
+// ResetTwoFactorUseCase lets an administrator remove another user's second factor
+// when they lost both their authenticator and their recovery codes.
+type ResetTwoFactorUseCase struct {
+	UserRepository         domain.UserRepository
+	RecoveryCodeRepository domain.RecoveryCodeRepository
+}
+
+func (uc *ResetTwoFactorUseCase) Execute(userID uint, actor Actor) error {
+	if !actor.Can(domain.PermissionManageUsers) {
+		return domain.ErrForbidden
+	}
+	user, err := uc.UserRepository.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return removeTwoFactor(uc.UserRepository, uc.RecoveryCodeRepository, user)
+}
+
+func removeTwoFactor(users domain.UserRepository, recoveryCodes domain.RecoveryCodeRepository, user *domain.User) error {
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.UpdatedAt = time.Now()
+	if err := users.Update(user); err != nil {
+		return err
+	}
+	return recoveryCodes.DeleteByUserID(user.ID)
+}
+
+// VerifyTwoFactorLoginUseCase is the second login step: it trades a challenge
+// token and a TOTP or recovery code for the user, ready for StartSessionUseCase.
+type VerifyTwoFactorLoginUseCase struct {
+	UserRepository         domain.UserRepository
+	RecoveryCodeRepository domain.RecoveryCodeRepository
+	SecretCipher           domain.SecretCipher
+	Challenges             *TwoFactorChallenge
+	UnverifiedPolicy       UnverifiedLoginPolicy // Same policy as at login
+	Throttle               *LoginThrottle        // Wrong codes count as failed logins
+}
+
+type VerifyTwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" form:"challenge_token" binding:"required"`
+	Code           string `json:"code" form:"code" binding:"required"`
+	ClientIP       string `json:"-" form:"-"` // Set by the handler for per-address throttling
+}
+
+func (uc *VerifyTwoFactorLoginUseCase) Execute(req VerifyTwoFactorLoginRequest) (*domain.User, error) {
+	now := time.Now()
+	userID, sessionVersion, err := uc.Challenges.Parse(req.ChallengeToken, now)
+	if err != nil {
+		return nil, err
+	}
+	user, err := uc.UserRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.SessionVersion != sessionVersion || !user.IsTwoFactorEnabled() {
+		return nil, ErrInvalidTwoFactorChallenge
+	}
+
+	attempt, err := uc.Throttle.Attempt(user.Username, req.ClientIP, now)
+	if err != nil {
+		return nil, err
+	}
+
+	factor := secondFactor{uc.UserRepository, uc.RecoveryCodeRepository, uc.SecretCipher}
+	if err := factor.check(user, req.Code, now); err != nil {
+		// Only a wrong code counts as a failure
+		if err != ErrInvalidTwoFactorCode {
+			attempt.Release()
+		}
+		return nil, err
+	}
+	if err := applyUnverifiedPolicy(uc.UnverifiedPolicy, user); err != nil {
+		attempt.Release()
+		return nil, err
+	}
+	if err := attempt.Succeeded(); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
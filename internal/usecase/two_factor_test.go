@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"programming_blog_go/internal/domain"
+	"programming_blog_go/internal/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRecoveryCodeRepository is a mock implementation of domain.RecoveryCodeRepository
+type MockRecoveryCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockRecoveryCodeRepository) ReplaceAll(userID uint, codeHashes []string) error {
+	args := m.Called(userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockRecoveryCodeRepository) Consume(userID uint, codeHash string, at time.Time) (bool, error) {
+	args := m.Called(userID, codeHash, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecoveryCodeRepository) DeleteByUserID(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// MockSecretCipher is a mock implementation of domain.SecretCipher
+type MockSecretCipher struct {
+	mock.Mock
+}
+
+func (m *MockSecretCipher) Encrypt(plaintext []byte) (string, error) {
+	args := m.Called(plaintext)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSecretCipher) Decrypt(ciphertext string) ([]byte, error) {
+	args := m.Called(ciphertext)
+	plaintext, _ := args.Get(0).([]byte)
+	return plaintext, args.Error(1)
+}
+
+var testTOTPSecret = []byte("12345678901234567890")
+
+func currentTOTPCode() string {
+	return utils.TOTPCode(testTOTPSecret, utils.TOTPStep(time.Now()))
+}
+
+func TestTwoFactorChallenge(t *testing.T) {
+	challenges := &TwoFactorChallenge{Secret: []byte("secret"), TTL: 5 * time.Minute}
+	now := time.Now()
+	user := &domain.User{ID: 3, SessionVersion: 2}
+
+	token, expires := challenges.Token(user, now)
+	assert.Equal(t, now.Add(5*time.Minute), expires)
+
+	userID, sv, err := challenges.Parse(token, now)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), userID)
+	assert.Equal(t, 2, sv)
+
+	// Test case: Expired
+	_, _, err = challenges.Parse(token, now.Add(6*time.Minute))
+	assert.Equal(t, ErrInvalidTwoFactorChallenge, err)
+
+	// Test case: Signed with another secret
+	other := &TwoFactorChallenge{Secret: []byte("other"), TTL: 5 * time.Minute}
+	_, _, err = other.Parse(token, now)
+	assert.Equal(t, ErrInvalidTwoFactorChallenge, err)
+
+	// Test case: An email verification token with the same secret is not a challenge
+	verification := &EmailVerification{Secret: []byte("secret"), TTL: time.Hour}
+	_, _, err = challenges.Parse(verification.Token(&domain.User{ID: 3, Email: "a@b.c"}, now), now)
+	assert.Equal(t, ErrInvalidTwoFactorChallenge, err)
+}
+
+func TestGetTwoFactorStatusUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	usecase := &GetTwoFactorStatusUseCase{UserRepository: mockRepo}
+	enabledAt := time.Now()
+
+	// Test case: Pending enrollment does not count as enabled
+	mockRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, Role: domain.RoleAuthor, TOTPSecret: "sealed"}, nil).Once()
+	status, err := usecase.Execute(Actor{UserID: 1, Role: domain.RoleAuthor})
+	assert.NoError(t, err)
+	assert.False(t, status.Enabled)
+	assert.Nil(t, status.EnabledAt)
+
+	// Test case: Enabled
+	mockRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, Role: domain.RoleAuthor, TOTPSecret: "sealed", TOTPEnabledAt: &enabledAt}, nil).Once()
+	status, err = usecase.Execute(Actor{UserID: 1, Role: domain.RoleAuthor})
+	assert.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, &enabledAt, status.EnabledAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetupTwoFactorUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockCipher := new(MockSecretCipher)
+	usecase := &SetupTwoFactorUseCase{UserRepository: mockRepo, SecretCipher: mockCipher, Issuer: "Blog"}
+
+	// Test case: Readers cannot enroll
+	_, err := usecase.Execute(Actor{UserID: 1, Role: domain.RoleReader})
+	assert.Equal(t, domain.ErrForbidden, err)
+
+	// Test case: A new secret is stored encrypted and returned for the app
+	user := &domain.User{ID: 1, Username: "alice", Role: domain.RoleAuthor}
+	mockRepo.On("FindByID", uint(1)).Return(user, nil).Once()
+	mockCipher.On("Encrypt", mock.AnythingOfType("[]uint8")).Return("sealed", nil).Once()
+	mockRepo.On("Update", user).Return(nil).Once()
+
+	setup, err := usecase.Execute(Actor{UserID: 1, Role: domain.RoleAuthor})
+	assert.NoError(t, err)
+	assert.Equal(t, "sealed", user.TOTPSecret)
+	assert.Nil(t, user.TOTPEnabledAt, "pending until confirmed")
+	assert.Len(t, setup.Secret, 32)
+	assert.True(t, strings.HasPrefix(setup.URI, "otpauth://totp/Blog:alice?"))
+
+	// Test case: Already enabled
+	enabledAt := time.Now()
+	mockRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, Role: domain.RoleAuthor, TOTPSecret: "sealed", TOTPEnabledAt: &enabledAt}, nil).Once()
+	_, err = usecase.Execute(Actor{UserID: 1, Role: domain.RoleAuthor})
+	assert.Equal(t, ErrTwoFactorAlreadyEnabled, err)
+
+	mockRepo.AssertExpectations(t)
+	mockCipher.AssertExpectations(t)
+}
+
+func TestConfirmTwoFactorUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockCodes := new(MockRecoveryCodeRepository)
+	mockCipher := new(MockSecretCipher)
+	usecase := &ConfirmTwoFactorUseCase{UserRepository: mockRepo, RecoveryCodeRepository: mockCodes, SecretCipher: mockCipher}
+	actor := Actor{UserID: 1, Role: domain.RoleAdmin}
+	mockCipher.On("Decrypt", "sealed").Return(testTOTPSecret, nil)
+
+	// Test case: Wrong code
+	mockRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, Role: domain.RoleAdmin, TOTPSecret: "sealed"}, nil).Once()
+	stale := utils.TOTPCode(testTOTPSecret, utils.TOTPStep(time.Now())-10)
+	_, err := usecase.Execute(actor, TwoFactorCodeRequest{Code: stale})
+	assert.Equal(t, ErrInvalidTwoFactorCode, err)
+
+	// Test case: Correct code enables 2FA and returns fresh recovery codes
+	user := &domain.User{ID: 1, Role: domain.RoleAdmin, TOTPSecret: "sealed"}
+	mockRepo.On("FindByID", uint(1)).Return(user, nil).Once()
+	mockRepo.On("AdvanceTOTPStep", uint(1), mock.AnythingOfType("int64")).Return(true, nil).Once()
+	mockRepo.On("Update", user).Return(nil).Once()
+	var stored []string
+	mockCodes.On("ReplaceAll", uint(1), mock.AnythingOfType("[]string")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).([]string)
+	}).Return(nil).Once()
+
+	codes, err := usecase.Execute(actor, TwoFactorCodeRequest{Code: currentTOTPCode()})
+	assert.NoError(t, err)
+	assert.True(t, user.IsTwoFactorEnabled())
+	assert.NotZero(t, user.TOTPLastStep)
+	assert.Len(t, codes, RecoveryCodeCount)
+	// Only hashes of the normalized codes are stored
+	assert.Equal(t, hashToken(normalizeRecoveryCode(codes[0])), stored[0])
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, codes[0])
+
+	// Test case: Not set up
+	mockRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, Role: domain.RoleAdmin}, nil).Once()
+	_, err = usecase.Execute(actor, TwoFactorCodeRequest{Code: currentTOTPCode()})
+	assert.Equal(t, ErrTwoFactorNotSetUp, err)
+
+	mockRepo.AssertExpectations(t)
+	mockCodes.AssertExpectations(t)
+}
+
+func TestVerifyTwoFactorLoginUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockCodes := new(MockRecoveryCodeRepository)
+	mockCipher := new(MockSecretCipher)
+	challenges := &TwoFactorChallenge{Secret: []byte("secret"), TTL: 5 * time.Minute}
+	usecase := &VerifyTwoFactorLoginUseCase{
+		UserRepository:         mockRepo,
+		RecoveryCodeRepository: mockCodes,
+		SecretCipher:           mockCipher,
+		Challenges:             challenges,
+		UnverifiedPolicy:       UnverifiedLoginAllow,
+	}
+	mockCipher.On("Decrypt", "sealed").Return(testTOTPSecret, nil)
+	enabledAt := time.Now()
+	enabledUser := func() *domain.User {
+		return &domain.User{ID: 1, SessionVersion: 1, Role: domain.RoleEditor, TOTPSecret: "sealed", TOTPEnabledAt: &enabledAt}
+	}
+	challenge, _ := challenges.Token(enabledUser(), time.Now())
+
+	// Test case: TOTP code
+	mockRepo.On("FindByID", uint(1)).Return(enabledUser(), nil).Once()
+	mockRepo.On("AdvanceTOTPStep", uint(1), mock.AnythingOfType("int64")).Return(true, nil).Once()
+
+	user, err := usecase.Execute(VerifyTwoFactorLoginRequest{ChallengeToken: challenge, Code: currentTOTPCode()})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), user.ID)
+
+	// Test case: The same code again is a replay
+	mockRepo.On("FindByID", uint(1)).Return(enabledUser(), nil).Once()
+	mockRepo.On("AdvanceTOTPStep", uint(1), mock.AnythingOfType("int64")).Return(false, nil).Once()
+
+	_, err = usecase.Execute(VerifyTwoFactorLoginRequest{ChallengeToken: challenge, Code: currentTOTPCode()})
+	assert.Equal(t, ErrInvalidTwoFactorCode, err)
+
+	// Test case: Recovery code, typed in with different case
+	mockRepo.On("FindByID", uint(1)).Return(enabledUser(), nil).Once()
+	mockCodes.On("Consume", uint(1), hashToken("abcdefghij"), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+
+	_, err = usecase.Execute(VerifyTwoFactorLoginRequest{ChallengeToken: challenge, Code: "ABCDE-fghij"})
+	assert.NoError(t, err)
+
+	// Test case: Used or unknown recovery code
+	mockRepo.On("FindByID", uint(1)).Return(enabledUser(), nil).Once()
+	mockCodes.On("Consume", uint(1), hashToken("abcdefghij"), mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+
+	_, err = usecase.Execute(VerifyTwoFactorLoginRequest{ChallengeToken: challenge, Code: "abcde-fghij"})
+	assert.Equal(t, ErrInvalidTwoFactorCode, err)
+
+	// Test case: Password was reset after the first step
+	reset := enabledUser()
+	reset.SessionVersion = 2
+	mockRepo.On("FindByID", uint(1)).Return(reset, nil).Once()
+
+	_, err = usecase.Execute(VerifyTwoFactorLoginRequest{ChallengeToken: challenge, Code: currentTOTPCode()})
+	assert.Equal(t, ErrInvalidTwoFactorChallenge, err)
+
+	// Test case: Forged challenge
+	_, err = usecase.Execute(VerifyTwoFactorLoginRequest{ChallengeToken: "x.y", Code: currentTOTPCode()})
+	assert.Equal(t, ErrInvalidTwoFactorChallenge, err)
+
+	mockRepo.AssertExpectations(t)
+	mockCodes.AssertExpectations(t)
+}
+
+func TestDisableTwoFactorUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockCodes := new(MockRecoveryCodeRepository)
+	mockCipher := new(MockSecretCipher)
+	usecase := &DisableTwoFactorUseCase{UserRepository: mockRepo, RecoveryCodeRepository: mockCodes, SecretCipher: mockCipher}
+	actor := Actor{UserID: 1, Role: domain.RoleAuthor}
+	enabledAt := time.Now()
+
+	// Test case: A recovery code disables 2FA and removes the other codes
+	user := &domain.User{ID: 1, Role: domain.RoleAuthor, TOTPSecret: "sealed", TOTPEnabledAt: &enabledAt}
+	mockRepo.On("FindByID", uint(1)).Return(user, nil).Once()
+	mockCodes.On("Consume", uint(1), hashToken("abcdefghij"), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	mockRepo.On("Update", user).Return(nil).Once()
+	mockCodes.On("DeleteByUserID", uint(1)).Return(nil).Once()
+
+	err := usecase.Execute(actor, TwoFactorCodeRequest{Code: "abcde-fghij"})
+	assert.NoError(t, err)
+	assert.False(t, user.IsTwoFactorEnabled())
+	assert.Empty(t, user.TOTPSecret)
+
+	// Test case: Not enabled
+	mockRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, Role: domain.RoleAuthor}, nil).Once()
+	err = usecase.Execute(actor, TwoFactorCodeRequest{Code: "123456"})
+	assert.Equal(t, ErrTwoFactorNotSetUp, err)
+
+	mockRepo.AssertExpectations(t)
+	mockCodes.AssertExpectations(t)
+}
+
+func TestTwoFactorCodeActions_Throttled(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockCodes := new(MockRecoveryCodeRepository)
+	mockCipher := new(MockSecretCipher)
+	throttle, attempts := newTestLoginThrottle()
+	disable := &DisableTwoFactorUseCase{UserRepository: mockRepo, RecoveryCodeRepository: mockCodes, SecretCipher: mockCipher, Throttle: throttle}
+	regenerate := &RegenerateRecoveryCodesUseCase{UserRepository: mockRepo, RecoveryCodeRepository: mockCodes, SecretCipher: mockCipher, Throttle: throttle}
+	actor := Actor{UserID: 1, Role: domain.RoleAuthor}
+	enabledAt := time.Now()
+	mockRepo.On("FindByID", uint(1)).Return(&domain.User{ID: 1, Username: "alice", Role: domain.RoleAuthor, TOTPSecret: "sealed", TOTPEnabledAt: &enabledAt}, nil)
+	mockCodes.On("Consume", uint(1), hashToken("aaaaabbbbb"), mock.AnythingOfType("time.Time")).Return(false, nil).Times(3)
+
+	// A burst of wrong codes, spread over both actions, locks the account
+	req := TwoFactorCodeRequest{Code: "aaaaa-bbbbb", ClientIP: "198.51.100.1"}
+	assert.Equal(t, ErrInvalidTwoFactorCode, disable.Execute(actor, req))
+	_, err := regenerate.Execute(actor, req)
+	assert.Equal(t, ErrInvalidTwoFactorCode, err)
+	assert.Equal(t, ErrInvalidTwoFactorCode, disable.Execute(actor, req))
+
+	// Locked out, the code is not even looked at
+	req.Code = "abcde-fghij"
+	assert.Equal(t, ErrTooManyLoginAttempts, disable.Execute(actor, req))
+	_, err = regenerate.Execute(actor, req)
+	assert.Equal(t, ErrTooManyLoginAttempts, err)
+	assert.NotNil(t, attempts[accountThrottleKey("alice")].LockedUntil)
+
+	// Once the lockout is over, a right code goes through and forgets the failures
+	attempts.expireLocks()
+	mockCodes.On("Consume", uint(1), hashToken("abcdefghij"), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	mockCodes.On("ReplaceAll", uint(1), mock.AnythingOfType("[]string")).Return(nil).Once()
+
+	codes, err := regenerate.Execute(actor, req)
+	assert.NoError(t, err)
+	assert.Len(t, codes, RecoveryCodeCount)
+	assert.NotContains(t, attempts, accountThrottleKey("alice"))
+
+	mockCodes.AssertExpectations(t)
+}
@@ -2,8 +2,9 @@ package usecase
 
 import (
 	"errors"
+	"log"
 	"programming_blog_go/internal/domain"
-	"programming_blog_go/internal/utils"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -18,73 +19,295 @@ var (
 
 // RegisterUserUseCase handles new user registration.
 type RegisterUserUseCase struct {
-	UserRepository domain.UserRepository
+	UserRepository    domain.UserRepository
+	PasswordHasher    domain.PasswordHasher
+	PasswordPolicy    *PasswordPolicy
+	EmailVerification *EmailVerification // Nil to skip sending the verification link
 }
 
 type RegisterUserRequest struct {
-	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username string `json:"username" form:"username" binding:"required"`
+	Email    string `json:"email" form:"email" binding:"required,email"`
+	Password string `json:"password" form:"password" binding:"required"`
 }
 
 func (uc *RegisterUserUseCase) Execute(req RegisterUserRequest) (*domain.User, error) {
-	// Check if user already exists by username or email
-	_, err := uc.UserRepository.FindByUsername(req.Username)
-	if err == nil { // User found
+	user, err := newUser(uc.UserRepository, uc.PasswordHasher, uc.PasswordPolicy, req)
+	if err != nil {
+		return nil, err
+	}
+	if uc.EmailVerification != nil {
+		sentAt := user.CreatedAt
+		user.VerificationSentAt = &sentAt
+	}
+
+	err = uc.UserRepository.Create(user)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account exists either way; a lost email can be requested again
+	if uc.EmailVerification != nil {
+		if err := uc.EmailVerification.Send(user); err != nil {
+			log.Printf("Error sending verification email to user %d: %v", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+// newUser checks a new account against the password policy and the existing users
+// and returns it with the password hashed. It is not stored yet.
+func newUser(users domain.UserRepository, hasher domain.PasswordHasher, policy *PasswordPolicy, req RegisterUserRequest) (*domain.User, error) {
+	if err := policy.Check(req.Password, req.Username); err != nil {
+		return nil, err
+	}
+
+	// Check if user already exists by username or email.
+	// The repository returns nil, nil when nothing matches.
+	existing, err := users.FindByUsername(req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
 		return nil, ErrUserAlreadyExists
 	}
-	_, err = uc.UserRepository.FindByEmail(req.Email)
-	if err == nil { // User found
+	existing, err = users.FindByEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
 		return nil, ErrUserAlreadyExists
 	}
 
 	// Hash the password
-	hashedPassword, err := utils.HashPassword(req.Password)
+	hashedPassword, err := hasher.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
 
-	user := &domain.User{
+	now := time.Now()
+	return &domain.User{
 		Username:  req.Username,
 		Email:     req.Email,
 		Password:  hashedPassword,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Role:      domain.RoleReader, // New accounts can read; an admin grants more
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// CreateUserUseCase lets an administrator add an account with any role, e.g. the
+// first admin from the command line. The administrator vouches for the address,
+// so it starts out verified.
+type CreateUserUseCase struct {
+	UserRepository domain.UserRepository
+	PasswordHasher domain.PasswordHasher
+	PasswordPolicy *PasswordPolicy
+}
+
+type CreateUserRequest struct {
+	RegisterUserRequest
+	Role domain.Role `json:"role" form:"role" binding:"required"`
+}
+
+func (uc *CreateUserUseCase) Execute(req CreateUserRequest, actor Actor) (*domain.User, error) {
+	if !actor.Can(domain.PermissionManageUsers) {
+		return nil, domain.ErrForbidden
+	}
+	if !req.Role.IsValid() {
+		return nil, domain.ErrInvalidInput
 	}
 
-	err = uc.UserRepository.Create(user)
+	user, err := newUser(uc.UserRepository, uc.PasswordHasher, uc.PasswordPolicy, req.RegisterUserRequest)
 	if err != nil {
 		return nil, err
 	}
+	verifiedAt := user.CreatedAt
+	user.Role = req.Role
+	user.EmailVerifiedAt = &verifiedAt
 
+	// A single insert, so a failure never leaves a half-configured account behind
+	if err := uc.UserRepository.Create(user); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
-// AuthenticateUserUseCase handles user login and authentication.
+// AuthenticateUserUseCase handles user login and authentication. Unknown usernames
+// and wrong passwords get the same error in about the same time, so the login does
+// not tell which accounts exist.
 type AuthenticateUserUseCase struct {
-	UserRepository domain.UserRepository
+	UserRepository   domain.UserRepository
+	PasswordHasher   domain.PasswordHasher
+	UnverifiedPolicy UnverifiedLoginPolicy // Unknown values are treated as reject
+	Throttle         *LoginThrottle        // Nil to allow unlimited attempts
+
+	dummyHashOnce sync.Once
+	dummyHash     string
 }
 
 type AuthenticateUserRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username string `json:"username" form:"username" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required"`
+	ClientIP string `json:"-" form:"-"` // Set by the handler for per-address throttling
 }
 
 func (uc *AuthenticateUserUseCase) Execute(req AuthenticateUserRequest) (*domain.User, error) {
+	attempt, err := uc.Throttle.Attempt(req.Username, req.ClientIP, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := uc.UserRepository.FindByUsername(req.Username)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		attempt.Release()
+		return nil, err
+	}
+	if !uc.checkPassword(user, req.Password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	uc.upgradePasswordHash(user, req.Password)
+
+	if err := applyUnverifiedPolicy(uc.UnverifiedPolicy, user); err != nil {
+		attempt.Release()
+		return nil, err
+	}
+	// With two-factor authentication the failures are forgotten only once the
+	// code is right, or knowing the password would reset the guessing of codes
+	if user.IsTwoFactorEnabled() {
+		err = attempt.Release()
+	} else {
+		err = attempt.Succeeded()
+	}
 	if err != nil {
-		// Assuming FindByUsername returns an error like gorm.ErrRecordNotFound if not found
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrUserNotFound
-		}
 		return nil, err
 	}
 
-	// Compare the provided password with the stored hashed password
-	err = utils.CheckPasswordHash(req.Password, user.Password)
+	return user, nil
+}
+
+// checkPassword compares the password with the user's hash. Unknown users and
+// accounts without a local password are compared against a throwaway hash, so
+// the answer takes as long as for a real account.
+func (uc *AuthenticateUserUseCase) checkPassword(user *domain.User, password string) bool {
+	hash := ""
+	if user != nil {
+		hash = user.Password
+	}
+	if hash == "" {
+		uc.dummyHashOnce.Do(func() {
+			var err error
+			if uc.dummyHash, err = uc.PasswordHasher.Hash("not the password of any account"); err != nil {
+				log.Printf("Error hashing the dummy password: %v", err)
+			}
+		})
+		uc.PasswordHasher.Verify(password, uc.dummyHash)
+		return false
+	}
+
+	ok, err := uc.PasswordHasher.Verify(password, hash)
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		log.Printf("Error checking the password of user %d: %v", user.ID, err)
 	}
+	return ok
+}
 
+// upgradePasswordHash rehashes the password, known only now, when the stored hash
+// uses an outdated algorithm or cost. The login goes ahead if that fails.
+func (uc *AuthenticateUserUseCase) upgradePasswordHash(user *domain.User, password string) {
+	if !uc.PasswordHasher.NeedsRehash(user.Password) {
+		return
+	}
+	hash, err := uc.PasswordHasher.Hash(password)
+	if err != nil {
+		log.Printf("Error rehashing the password of user %d: %v", user.ID, err)
+		return
+	}
+	user.Password = hash
+	user.UpdatedAt = time.Now()
+	if err := uc.UserRepository.Update(user); err != nil {
+		log.Printf("Error storing the rehashed password of user %d: %v", user.ID, err)
+	}
+}
+
+// UpdateUserRoleUseCase lets an administrator change another user's role.
+type UpdateUserRoleUseCase struct {
+	UserRepository domain.UserRepository
+}
+
+type UpdateUserRoleRequest struct {
+	Role domain.Role `json:"role" form:"role" binding:"required"`
+}
+
+func (uc *UpdateUserRoleUseCase) Execute(userID uint, req UpdateUserRoleRequest, actor Actor) (*domain.User, error) {
+	if !actor.Can(domain.PermissionManageUsers) {
+		return nil, domain.ErrForbidden
+	}
+	if !req.Role.IsValid() {
+		return nil, domain.ErrInvalidInput
+	}
+	// Admins cannot demote themselves and lock everyone out of user management
+	if userID == actor.UserID {
+		return nil, domain.ErrForbidden
+	}
+
+	user, err := uc.UserRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	user.Role = req.Role
+	user.UpdatedAt = time.Now()
+	if err := uc.UserRepository.Update(user); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
+
+// SetUserPasswordUseCase lets an administrator replace a user's password, e.g. from
+// the command line when the user has lost it.
+type SetUserPasswordUseCase struct {
+	UserRepository     domain.UserRepository
+	APITokenRepository domain.APITokenRepository
+	PasswordHasher     domain.PasswordHasher
+	PasswordPolicy     *PasswordPolicy
+}
+
+type SetUserPasswordRequest struct {
+	Password string `json:"password" form:"password" binding:"required"`
+}
+
+func (uc *SetUserPasswordUseCase) Execute(userID uint, req SetUserPasswordRequest, actor Actor) error {
+	if !actor.Can(domain.PermissionManageUsers) {
+		return domain.ErrForbidden
+	}
+
+	user, err := uc.UserRepository.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if err := uc.PasswordPolicy.Check(req.Password, user.Username); err != nil {
+		return err
+	}
+
+	hashedPassword, err := uc.PasswordHasher.Hash(req.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	user.SessionVersion++ // Sign out sessions opened with the old password
+	user.UpdatedAt = time.Now()
+	if err := uc.UserRepository.Update(user); err != nil {
+		return err
+	}
+	// API tokens do not carry the session version, so they are revoked outright
+	return uc.APITokenRepository.DeleteByUserID(user.ID)
+}
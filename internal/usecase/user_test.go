@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"errors"
+	"programming_blog_go/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUserRepository is a mock implementation of domain.UserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(user *domain.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByID(id uint) (*domain.User, error) {
+	args := m.Called(id)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByUsername(username string) (*domain.User, error) {
+	args := m.Called(username)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(email string) (*domain.User, error) {
+	args := m.Called(email)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Update(user *domain.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) AdvanceTOTPStep(userID uint, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) ClaimVerificationEmail(userID uint, at time.Time, cooldown time.Duration) (bool, error) {
+	args := m.Called(userID, at, cooldown)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// fakePasswordHasher stores passwords as "<version>$<password>", which keeps tests
+// fast and makes outdated hashes easy to write.
+type fakePasswordHasher struct {
+	version string
+}
+
+var testPasswordHasher = fakePasswordHasher{version: "v2"}
+
+func (h fakePasswordHasher) Hash(password string) (string, error) {
+	return h.version + "$" + password, nil
+}
+
+func (h fakePasswordHasher) Verify(password, hash string) (bool, error) {
+	_, stored, ok := strings.Cut(hash, "$")
+	if !ok {
+		return false, errors.New("unsupported hash")
+	}
+	return stored == password, nil
+}
+
+func (h fakePasswordHasher) NeedsRehash(hash string) bool {
+	return !strings.HasPrefix(hash, h.version+"$")
+}
+
+func TestRegisterUserUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	usecase := &RegisterUserUseCase{UserRepository: mockRepo, PasswordHasher: testPasswordHasher, PasswordPolicy: &PasswordPolicy{MinLength: 8}}
+
+	request := RegisterUserRequest{Username: "alice", Email: "alice@example.com", Password: "secret123"}
+
+	// Test case: Successful registration starts as a reader
+	mockRepo.On("FindByUsername", "alice").Return(nil, nil).Once()
+	mockRepo.On("FindByEmail", "alice@example.com").Return(nil, nil).Once()
+	mockRepo.On("Create", mock.AnythingOfType("*domain.User")).Return(nil).Once()
+
+	user, err := usecase.Execute(request)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleReader, user.Role)
+	assert.Equal(t, "v2$secret123", user.Password)
+
+	// Test case: Username taken
+	mockRepo.On("FindByUsername", "alice").Return(&domain.User{ID: 1}, nil).Once()
+
+	user, err = usecase.Execute(request)
+	assert.Equal(t, ErrUserAlreadyExists, err)
+	assert.Nil(t, user)
+
+	// Test case: Password against the policy
+	request.Password = "secret"
+	user, err = usecase.Execute(request)
+	assert.Equal(t, ErrPasswordTooShort, err)
+	assert.Nil(t, user)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateUserUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	usecase := &CreateUserUseCase{UserRepository: mockRepo, PasswordHasher: testPasswordHasher, PasswordPolicy: &PasswordPolicy{MinLength: 8}}
+
+	request := CreateUserRequest{
+		RegisterUserRequest: RegisterUserRequest{Username: "root", Email: "root@example.com", Password: "secret123"},
+		Role:                domain.RoleAdmin,
+	}
+
+	// Test case: Non-admins cannot create accounts
+	user, err := usecase.Execute(request, Actor{UserID: 2, Role: domain.RoleEditor})
+	assert.Equal(t, domain.ErrForbidden, err)
+	assert.Nil(t, user)
+
+	// Test case: The account is stored once, verified and with its role
+	mockRepo.On("FindByUsername", "root").Return(nil, nil).Once()
+	mockRepo.On("FindByEmail", "root@example.com").Return(nil, nil).Once()
+	mockRepo.On("Create", mock.MatchedBy(func(u *domain.User) bool {
+		return u.Role == domain.RoleAdmin && u.EmailVerifiedAt != nil && u.VerificationSentAt == nil
+	})).Return(nil).Once()
+
+	user, err = usecase.Execute(request, Actor{Role: domain.RoleAdmin})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, user.Role)
+
+	// Test case: Unknown role
+	request.Role = "owner"
+	user, err = usecase.Execute(request, Actor{Role: domain.RoleAdmin})
+	assert.Equal(t, domain.ErrInvalidInput, err)
+	assert.Nil(t, user)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdateUserRoleUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	usecase := &UpdateUserRoleUseCase{UserRepository: mockRepo}
+
+	admin := Actor{UserID: 1, Role: domain.RoleAdmin}
+
+	// Test case: Admin promotes a reader
+	mockRepo.On("FindByID", uint(2)).Return(&domain.User{ID: 2, Role: domain.RoleReader}, nil).Once()
+	mockRepo.On("Update", mock.AnythingOfType("*domain.User")).Return(nil).Once()
+
+	user, err := usecase.Execute(2, UpdateUserRoleRequest{Role: domain.RoleEditor}, admin)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleEditor, user.Role)
+
+	// Test case: Non-admins cannot manage users
+	user, err = usecase.Execute(2, UpdateUserRoleRequest{Role: domain.RoleAdmin}, Actor{UserID: 3, Role: domain.RoleEditor})
+	assert.Equal(t, domain.ErrForbidden, err)
+	assert.Nil(t, user)
+
+	// Test case: Unknown role
+	user, err = usecase.Execute(2, UpdateUserRoleRequest{Role: "superuser"}, admin)
+	assert.Equal(t, domain.ErrInvalidInput, err)
+	assert.Nil(t, user)
+
+	// Test case: Admins cannot change their own role
+	user, err = usecase.Execute(1, UpdateUserRoleRequest{Role: domain.RoleReader}, admin)
+	assert.Equal(t, domain.ErrForbidden, err)
+	assert.Nil(t, user)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetUserPasswordUseCase_Execute(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockTokenRepo := new(MockAPITokenRepository)
+	usecase := &SetUserPasswordUseCase{UserRepository: mockRepo, APITokenRepository: mockTokenRepo, PasswordHasher: testPasswordHasher, PasswordPolicy: &PasswordPolicy{MinLength: 8}}
+
+	admin := Actor{Role: domain.RoleAdmin}
+
+	// Test case: Password is replaced with a new hash and API tokens are revoked
+	mockRepo.On("FindByID", uint(2)).Return(&domain.User{ID: 2, Password: "old-hash"}, nil).Once()
+	mockRepo.On("Update", mock.MatchedBy(func(u *domain.User) bool {
+		return u.Password == "v2$new-secret"
+	})).Return(nil).Once()
+	mockTokenRepo.On("DeleteByUserID", uint(2)).Return(nil).Once()
+
+	err := usecase.Execute(2, SetUserPasswordRequest{Password: "new-secret"}, admin)
+	assert.NoError(t, err)
+
+	// Test case: Too short
+	mockRepo.On("FindByID", uint(2)).Return(&domain.User{ID: 2, Password: "old-hash"}, nil).Once()
+	err = usecase.Execute(2, SetUserPasswordRequest{Password: "123"}, admin)
+	assert.Equal(t, ErrPasswordTooShort, err)
+
+	// Test case: Unknown user
+	mockRepo.On("FindByID", uint(9)).Return(nil, nil).Once()
+	err = usecase.Execute(9, SetUserPasswordRequest{Password: "new-secret"}, admin)
+	assert.Equal(t, ErrUserNotFound, err)
+
+	// Test case: Non-admins cannot manage users
+	err = usecase.Execute(2, SetUserPasswordRequest{Password: "new-secret"}, Actor{UserID: 2, Role: domain.RoleAuthor})
+	assert.Equal(t, domain.ErrForbidden, err)
+
+	mockRepo.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestAuthenticateUserUseCase_Execute_RehashesPassword(t *testing.T) {
+	verifiedAt := time.Now()
+	mockRepo := new(MockUserRepository)
+	usecase := &AuthenticateUserUseCase{UserRepository: mockRepo, PasswordHasher: testPasswordHasher, UnverifiedPolicy: UnverifiedLoginReject}
+	req := AuthenticateUserRequest{Username: "alice", Password: "password"}
+
+	// Test case: A hash from an older algorithm is replaced once the password is known
+	mockRepo.On("FindByUsername", "alice").Return(&domain.User{ID: 1, Password: "v1$password", EmailVerifiedAt: &verifiedAt}, nil).Once()
+	mockRepo.On("Update", mock.MatchedBy(func(u *domain.User) bool {
+		return u.Password == "v2$password"
+	})).Return(nil).Once()
+
+	user, err := usecase.Execute(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2$password", user.Password)
+
+	// Test case: A current hash is left alone
+	mockRepo.On("FindByUsername", "alice").Return(&domain.User{ID: 1, Password: "v2$password", EmailVerifiedAt: &verifiedAt}, nil).Once()
+	_, err = usecase.Execute(req)
+	assert.NoError(t, err)
+
+	// Test case: A failed upgrade does not fail the login
+	mockRepo.On("FindByUsername", "alice").Return(&domain.User{ID: 1, Password: "v1$password", EmailVerifiedAt: &verifiedAt}, nil).Once()
+	mockRepo.On("Update", mock.Anything).Return(errors.New("db down")).Once()
+	_, err = usecase.Execute(req)
+	assert.NoError(t, err)
+
+	// Test case: Wrong password, and unknown or password-less users, never rehash
+	mockRepo.On("FindByUsername", "alice").Return(&domain.User{ID: 1, Password: "v1$password", EmailVerifiedAt: &verifiedAt}, nil).Once()
+	_, err = usecase.Execute(AuthenticateUserRequest{Username: "alice", Password: "wrong"})
+	assert.Equal(t, ErrInvalidCredentials, err)
+	mockRepo.On("FindByUsername", "bob").Return(&domain.User{ID: 2, EmailVerifiedAt: &verifiedAt}, nil).Once()
+	_, err = usecase.Execute(AuthenticateUserRequest{Username: "bob", Password: ""})
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	mockRepo.AssertExpectations(t)
+}
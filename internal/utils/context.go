@@ -1,29 +1,80 @@
 package utils
 
 import (
+	"programming_blog_go/internal/domain"
+
 	"github.com/gin-gonic/gin"
 )
 
-// GetUserIDFromContext retrieves the user ID from the Gin context.
-// It returns the user ID and a boolean indicating if it was found and is valid.
-func GetUserIDFromContext(c *gin.Context) (uint, bool) {
-	userID, exists := c.Get("user_id")
+// accessClaimsKey is where the auth middleware stores the verified access token claims.
+// Every identity helper below reads from them, so there is one typed source of truth
+// instead of loose values whose types depend on how a token was decoded.
+const accessClaimsKey = "access_claims"
+
+// SetAccessClaims records the verified claims of the request's access token.
+// base.html reads them as "current_user" to show who is logged in.
+func SetAccessClaims(c *gin.Context, claims *domain.AccessClaims) {
+	c.Set(accessClaimsKey, claims)
+	c.Set("current_user", claims)
+}
+
+// GetAccessClaimsFromContext retrieves the claims of the access token the request
+// was authenticated with, e.g. to revoke it on logout.
+func GetAccessClaimsFromContext(c *gin.Context) (*domain.AccessClaims, bool) {
+	value, exists := c.Get(accessClaimsKey)
 	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*domain.AccessClaims)
+	return claims, ok && claims != nil
+}
+
+// GetUserIDFromContext retrieves the authenticated user's ID from the Gin context.
+// It returns the user ID and a boolean indicating if the request is authenticated.
+func GetUserIDFromContext(c *gin.Context) (uint, bool) {
+	claims, ok := GetAccessClaimsFromContext(c)
+	if !ok || claims.UserID == 0 {
 		return 0, false
 	}
-	// Type assertion from interface{} to uint
-	id, ok := userID.(uint)
-	return id, ok
+	return claims.UserID, true
 }
 
-// GetUsernameFromContext retrieves the username from the Gin context.
-// It returns the username and a boolean indicating if it was found and is valid.
+// GetUsernameFromContext retrieves the authenticated user's name from the Gin context.
+// It returns the username and a boolean indicating if the request is authenticated.
 func GetUsernameFromContext(c *gin.Context) (string, bool) {
-	username, exists := c.Get("username")
-	if !exists {
+	claims, ok := GetAccessClaimsFromContext(c)
+	if !ok {
 		return "", false
 	}
-	// Type assertion from interface{} to string
-	name, ok := username.(string)
-	return name, ok
+	return claims.Username, true
+}
+
+// GetRoleFromContext retrieves the user's role from the Gin context.
+// Anonymous requests and tokens without a known role are treated as readers.
+func GetRoleFromContext(c *gin.Context) domain.Role {
+	claims, ok := GetAccessClaimsFromContext(c)
+	if !ok || !claims.Role.IsValid() {
+		return domain.RoleReader
+	}
+	return claims.Role
+}
+
+// GetScopesFromContext retrieves the scopes of the API token the request was
+// authenticated with, or nil for login sessions and anonymous requests.
+func GetScopesFromContext(c *gin.Context) domain.TokenScopes {
+	claims, ok := GetAccessClaimsFromContext(c)
+	if !ok {
+		return nil
+	}
+	return claims.Scopes
+}
+
+// HasPermission reports whether the request may use the permission: the user's
+// role must grant it and, for API tokens, the token's scopes must include it.
+func HasPermission(c *gin.Context, permission domain.Permission) bool {
+	if !GetRoleFromContext(c).Can(permission) {
+		return false
+	}
+	claims, ok := GetAccessClaimsFromContext(c)
+	return !ok || claims.Allows(permission)
 }
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"programming_blog_go/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityFromContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	_, ok := GetUserIDFromContext(c)
+	assert.False(t, ok, "anonymous request")
+	assert.Equal(t, domain.RoleReader, GetRoleFromContext(c))
+
+	SetAccessClaims(c, &domain.AccessClaims{UserID: 7, Username: "alice", Role: domain.RoleEditor})
+	id, ok := GetUserIDFromContext(c)
+	assert.True(t, ok)
+	assert.Equal(t, uint(7), id)
+	name, ok := GetUsernameFromContext(c)
+	assert.True(t, ok)
+	assert.Equal(t, "alice", name)
+	assert.Equal(t, domain.RoleEditor, GetRoleFromContext(c))
+
+	// A role the application does not know grants nothing beyond reading
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	SetAccessClaims(c, &domain.AccessClaims{UserID: 7, Role: "superuser"})
+	assert.Equal(t, domain.RoleReader, GetRoleFromContext(c))
+}
+
+func TestHasPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// A login session has all of the role's permissions
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	SetAccessClaims(c, &domain.AccessClaims{UserID: 7, Role: domain.RoleEditor})
+	assert.True(t, HasPermission(c, domain.PermissionPublishPosts))
+	assert.False(t, HasPermission(c, domain.PermissionManageUsers))
+	assert.Nil(t, GetScopesFromContext(c))
+
+	// An API token only those in its scopes, and only if the role grants them
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	SetAccessClaims(c, &domain.AccessClaims{
+		UserID: 7,
+		Role:   domain.RoleEditor,
+		Scopes: domain.TokenScopes{domain.PermissionWritePosts, domain.PermissionManageUsers},
+	})
+	assert.True(t, HasPermission(c, domain.PermissionWritePosts))
+	assert.False(t, HasPermission(c, domain.PermissionPublishPosts))
+	assert.False(t, HasPermission(c, domain.PermissionManageUsers))
+}
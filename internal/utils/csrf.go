@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Where the CSRF token travels: a cookie set by the server, and a form field or
+// request header that a cross-site page cannot fill in.
+const (
+	CSRFCookie    = "csrf_token"
+	CSRFFormField = "csrf_token"
+	CSRFHeader    = "X-CSRF-Token"
+)
+
+// IssueCSRFToken starts a new CSRF token for the visitor, e.g. when the
+// middleware sees none or when a login or logout starts a new session. The
+// token is also put into the context for the templates of this request.
+func IssueCSRFToken(c *gin.Context, secure bool) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     CSRFCookie,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.Set("csrf_token", token)
+	return token, nil
+}
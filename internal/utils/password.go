@@ -1,18 +0,0 @@
-package utils
-
-import "golang.org/x/crypto/bcrypt"
-
-// HashPassword hashes a plain-text password using bcrypt.
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hashedPassword), nil
-}
-
-// CheckPasswordHash compares a plain-text password with a hashed password.
-// It returns nil on success, or an error if the password does not match.
-func CheckPasswordHash(password, hash string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"net/http"
+	"time"
+
+	"programming_blog_go/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Names of the cookies that carry a browser session.
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+)
+
+// SetSessionCookies stores a token pair in HttpOnly cookies, each expiring with its token.
+// SameSite=Lax keeps the cookies off cross-site POSTs while still sending them when a
+// visitor follows a link to the blog.
+func SetSessionCookies(c *gin.Context, pair *domain.TokenPair, secure bool) {
+	setSessionCookie(c, AccessTokenCookie, pair.AccessToken, pair.AccessExpiresAt, secure)
+	setSessionCookie(c, RefreshTokenCookie, pair.RefreshToken, pair.RefreshExpiresAt, secure)
+}
+
+// ClearSessionCookies tells the browser to drop both session cookies.
+func ClearSessionCookies(c *gin.Context, secure bool) {
+	setSessionCookie(c, AccessTokenCookie, "", time.Unix(0, 0), secure)
+	setSessionCookie(c, RefreshTokenCookie, "", time.Unix(0, 0), secure)
+}
+
+func setSessionCookie(c *gin.Context, name, value string, expires time.Time, secure bool) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
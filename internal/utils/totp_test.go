@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B (SHA1), truncated to six digits
+	secret := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0))), tt.unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111109, 0)
+	step := TOTPStep(now)
+
+	matched, ok := ValidateTOTP(secret, TOTPCode(secret, step), now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, step, matched)
+
+	// One step of clock drift either way is tolerated, two are not
+	matched, ok = ValidateTOTP(secret, TOTPCode(secret, step-1), now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, step-1, matched)
+	_, ok = ValidateTOTP(secret, TOTPCode(secret, step+2), now, 1)
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "12345", now, 1)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("My Blog", "alice", []byte("12345678901234567890"))
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/My%20Blog:alice?"), uri)
+	assert.Contains(t, uri, "secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	assert.Contains(t, uri, "issuer=My+Blog")
+}